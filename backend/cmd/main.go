@@ -8,10 +8,15 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/inkless/backend/internal/anchoring"
 	"github.com/inkless/backend/internal/api/handlers"
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/config"
 	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/identity"
+	"github.com/inkless/backend/internal/indexer"
 	"github.com/inkless/backend/internal/ledger"
+	"github.com/inkless/backend/internal/signing"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -19,6 +24,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.Load()
+	if cfg.Environment == "production" && cfg.HasPlaceholderJWTSecret() {
+		log.Fatal("JWT_SECRET must be set to a secret of your own in production")
+	}
 
 	// Connect to database
 	if err := db.Connect(cfg); err != nil {
@@ -31,9 +39,131 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
-	// Initialize blockchain ledger (optional - falls back to mock if not configured)
-	if err := ledger.Initialize(cfg.BesuNodeURL, cfg.ContractAddress, cfg.SignerPrivateKey); err != nil {
-		log.Printf("Warning: Ledger initialization failed: %v", err)
+	// Apply signature algorithm policy (crypto-agility: deprecate/forbid without a release)
+	if err := signing.ApplyPolicy(cfg.SignatureAlgorithmsDeprecated, cfg.SignatureAlgorithmsForbidden); err != nil {
+		log.Fatalf("Invalid signature algorithm policy: %v", err)
+	}
+
+	auth.SetAdmins(cfg.AdminDIDs)
+
+	// Background services stop with this context
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+
+	// Connect the ledger networks: real nodes, each supervised so it is
+	// redialled until it answers, or the simulated chain where none is
+	// configured outside production
+	maxFee, err := ledger.ParseGwei(cfg.LedgerMaxFeeGwei)
+	if err != nil {
+		log.Fatalf("Invalid LEDGER_MAX_FEE_GWEI: %v", err)
+	}
+	maxPriorityFee, err := ledger.ParseGwei(cfg.LedgerMaxPriorityFeeGwei)
+	if err != nil {
+		log.Fatalf("Invalid LEDGER_MAX_PRIORITY_FEE_GWEI: %v", err)
+	}
+	lowBalance, err := ledger.ParseEther(cfg.LedgerLowBalanceEth)
+	if err != nil {
+		log.Fatalf("Invalid LEDGER_LOW_BALANCE_ETH: %v", err)
+	}
+	supervisorOpts := ledger.SupervisorOptions{
+		Fees: ledger.FeeConfig{
+			GasLimitMargin:       cfg.LedgerGasLimitMargin,
+			MaxGasLimit:          uint64(cfg.LedgerMaxGasLimit),
+			MaxFeePerGas:         maxFee,
+			MaxPriorityFeePerGas: maxPriorityFee,
+		},
+		CheckInterval: cfg.LedgerHealthInterval,
+		MaxBackoff:    cfg.LedgerReconnectMaxBackoff,
+		MaxLag:        cfg.LedgerMaxLag,
+		LowBalance:    lowBalance,
+	}
+	networks, err := ledger.ConfiguredNetworks(cfg)
+	if err != nil {
+		log.Fatalf("Invalid ledger configuration: %v", err)
+	}
+	if err := ledger.Configure(networks); err != nil {
+		log.Fatalf("Invalid ledger configuration: %v", err)
+	}
+	if err := anchoring.SetRoutes(cfg.LedgerDefaultNetworks, cfg.LedgerCategoryNetworks); err != nil {
+		log.Fatalf("Invalid ledger network routes: %v", err)
+	}
+	if len(networks) == 0 {
+		log.Println("Warning: No ledger configured; signatures will stay queued")
+	}
+	for _, network := range networks {
+		if network.Signer.Kind() == "key" && cfg.Environment == "production" {
+			log.Printf("Warning: network %s signs with a raw private key from the environment; use a keystore file or a remote signer", network.Name)
+		}
+	}
+	for _, network := range networks {
+		go ledger.Supervise(workerCtx, network, supervisorOpts)
+	}
+
+	// Signer accounts: each DID's own account approves its anchors, which
+	// the relayer then sends, so the chain attributes them to the signer
+	accountSeed, err := ledger.LoadAccountSeed(cfg.SignerAccountSeed, cfg.SignerAccountSeedFile)
+	if err != nil {
+		log.Fatalf("Invalid signer account seed: %v", err)
+	}
+	ledger.ConfigureAccounts(accountSeed)
+	if accountSeed != nil && cfg.AnchorBatching {
+		// A batch root is one record for many signers, approved by none of them
+		log.Fatal("ANCHOR_BATCHING can't be combined with signer accounts: batch roots carry no signer approval")
+	}
+	switch {
+	case accountSeed == nil && cfg.Environment == "production":
+		log.Println("Warning: No signer account seed configured; anchors are vouched for by the relayer alone")
+	case cfg.SignerAccountSeed != "" && cfg.Environment == "production":
+		log.Println("Warning: the signer account seed comes from the environment; use SIGNER_ACCOUNT_SEED_FILE")
+	}
+	if assigned, err := anchoring.AssignAccounts(db.DB); err != nil {
+		log.Fatalf("Failed to assign signer accounts: %v", err)
+	} else if assigned > 0 {
+		log.Printf("Assigned signer accounts to %d users", assigned)
+	}
+
+	// Background anchoring: signatures are queued in Postgres, submitted to
+	// the ledger with retries and watched until their receipts are confirmed
+	anchorWorker := anchoring.NewWorker(db.DB, anchoring.Options{
+		PollInterval: cfg.AnchorQueuePollInterval,
+		MaxAttempts:  cfg.AnchorQueueMaxAttempts,
+		BaseBackoff:  cfg.AnchorQueueBaseBackoff,
+		MaxBackoff:   cfg.AnchorQueueMaxBackoff,
+
+		Confirmations: cfg.AnchorConfirmations,
+		DropTimeout:   cfg.AnchorDropTimeout,
+
+		StuckTimeout:   cfg.AnchorStuckTimeout,
+		GasBumpPercent: cfg.LedgerGasBumpPercent,
+
+		Batching:       cfg.AnchorBatching,
+		BatchMaxLeaves: cfg.AnchorBatchMaxLeaves,
+		BatchMaxWait:   cfg.AnchorBatchMaxWait,
+	})
+	go anchorWorker.Run(workerCtx)
+
+	// Mirror the primary network's contract events into Postgres and flag
+	// drift between the two, once the node answers. The simulated chain
+	// forgets everything on restart, so it is not mirrored.
+	if cfg.IndexerEnabled {
+		go func() {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ledger.Ready():
+			}
+			if ledger.Global.Backend() != ledger.BackendBesu {
+				return
+			}
+			eventIndexer := indexer.New(db.DB, ledger.Global.ContractAddress(), indexer.Options{
+				PollInterval:  cfg.IndexerPollInterval,
+				Confirmations: cfg.AnchorConfirmations,
+				StartBlock:    uint64(cfg.IndexerStartBlock),
+				MaxBlockRange: uint64(cfg.IndexerMaxBlockRange),
+				DriftInterval: cfg.IndexerDriftInterval,
+			})
+			eventIndexer.Run(workerCtx)
+		}()
 	}
 
 	// Initialize Echo
@@ -53,57 +183,104 @@ func main() {
 	}))
 	e.Use(middleware.RequestID())
 
-	// Health check
+	// Health check; the API stays up without a ledger (signatures queue), so
+	// a ledger problem degrades rather than fails it
 	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{
-			"status": "healthy",
-			"time":   time.Now().Format(time.RFC3339),
+		ledgerHealth := ledger.CurrentHealth()
+		networkHealth := ledger.NetworkHealth()
+		status := "healthy"
+		if ledgerHealth.Status != ledger.HealthOperational {
+			status = "degraded"
+		}
+		for _, h := range networkHealth {
+			if h.Status != ledger.HealthOperational {
+				status = "degraded"
+			}
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status":   status,
+			"time":     time.Now().Format(time.RFC3339),
+			"ledger":   ledgerHealth,
+			"networks": networkHealth,
 		})
 	})
 
 	// API v1 routes
 	v1 := e.Group("/api/v1")
 
-	// Identity routes (NIMC mock)
-	identityHandler := handlers.NewIdentityHandler()
+	// User-scoped routes require a valid access token; the caller is loaded into the context
+	requireUser := auth.RequireUser(cfg.JWTSecret)
+
+	// Identity routes (NIMC vNIN verification, mock unless enabled)
+	identityProvider, err := identity.NewProvider(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure identity provider: %v", err)
+	}
+	identityHandler := handlers.NewIdentityHandler(identityProvider, cfg.JWTSecret)
 	v1.POST("/identity/verify", identityHandler.Verify)
+	v1.GET("/identity/link-requests/:id", identityHandler.LinkStatus)
+	v1.POST("/identity/link-requests/:id/claim", identityHandler.LinkClaim)
+
+	// Auth routes (token exchange after identity verification, refresh rotation,
+	// passwordless device-key login)
+	authHandler := handlers.NewAuthHandler(cfg.JWTSecret)
+	v1.POST("/auth/token", authHandler.Token)
+	v1.POST("/auth/refresh", authHandler.Refresh)
+	v1.POST("/auth/challenge", authHandler.Challenge)
+	v1.POST("/auth/challenge/response", authHandler.AnswerChallenge)
 
 	// Signature routes
-	signatureHandler := handlers.NewSignatureHandler()
-	v1.POST("/signatures/anchor", signatureHandler.Anchor)
-	v1.GET("/signatures/recent", signatureHandler.GetRecent)
+	signatureHandler := handlers.NewSignatureHandler(cfg.VerifyRequireChain)
+	v1.POST("/signatures/anchor", signatureHandler.Anchor, requireUser)
+	v1.GET("/signatures/recent", signatureHandler.GetRecent, requireUser)
+	v1.GET("/signatures/:id/status", signatureHandler.Status, requireUser)
+	v1.GET("/signatures/:id/events", signatureHandler.Events, requireUser)
+	v1.POST("/signatures/:id/revoke", signatureHandler.Revoke, requireUser)
 	v1.GET("/verify/:docHash", signatureHandler.Verify)
 
 	// Offline sync routes (QR-based signing)
 	offlineHandler := handlers.NewOfflineHandler()
-	v1.POST("/offline/sync", offlineHandler.Sync)
-	v1.GET("/offline/pending", offlineHandler.GetPendingCount)
+	v1.POST("/offline/sync", offlineHandler.Sync, requireUser)
+	v1.GET("/offline/pending", offlineHandler.GetPendingCount, requireUser)
 
 	// Export routes
 	exportHandler := handlers.NewExportHandler()
-	v1.GET("/files/:docHash/audit-trail", exportHandler.ExportAuditTrail)
+	v1.GET("/files/:docHash/audit-trail", exportHandler.ExportAuditTrail, requireUser)
 
 	// Device routes
 	deviceHandler := handlers.NewDeviceHandler()
-	v1.GET("/devices", deviceHandler.ListDevices)
-	v1.POST("/devices", deviceHandler.RegisterDevice)
-	v1.DELETE("/devices/:id", deviceHandler.RemoveDevice)
-	v1.POST("/devices/revoke-all", deviceHandler.RevokeAllDevices)
+	v1.GET("/devices", deviceHandler.ListDevices, requireUser)
+	v1.POST("/devices", deviceHandler.RegisterDevice, requireUser)
+	v1.DELETE("/devices/:id", deviceHandler.RemoveDevice, requireUser)
+	v1.POST("/devices/revoke-all", deviceHandler.RevokeAllDevices, requireUser)
+	v1.GET("/devices/link-requests", deviceHandler.ListLinkRequests, requireUser)
+	v1.POST("/devices/link-requests/:id/approve", deviceHandler.ApproveLinkRequest, requireUser)
+	v1.POST("/devices/link-requests/:id/reject", deviceHandler.RejectLinkRequest, requireUser)
 
 	// Profile routes
 	profileHandler := handlers.NewProfileHandler()
-	v1.GET("/profile", profileHandler.GetProfile)
-	v1.PATCH("/profile", profileHandler.UpdateProfile)
+	v1.GET("/profile", profileHandler.GetProfile, requireUser)
+	v1.PATCH("/profile", profileHandler.UpdateProfile, requireUser)
+	v1.DELETE("/profile", profileHandler.DeleteAccount, requireUser)
 
 	// Preferences routes
 	preferencesHandler := handlers.NewPreferencesHandler()
-	v1.GET("/preferences", preferencesHandler.GetPreferences)
-	v1.GET("/preferences", preferencesHandler.GetPreferences)
-	v1.PATCH("/preferences", preferencesHandler.UpdatePreferences)
+	v1.GET("/preferences", preferencesHandler.GetPreferences, requireUser)
+	v1.PATCH("/preferences", preferencesHandler.UpdatePreferences, requireUser)
 
 	// Stats routes
 	statsHandler := handlers.NewStatsHandler()
-	v1.GET("/stats", statsHandler.GetDashboardStats)
+	v1.GET("/stats", statsHandler.GetDashboardStats, requireUser)
+
+	// Admin routes (ADMIN_DIDS): account suspension and deletion, and the
+	// registry's verified signers (addVerifiedSigner / removeVerifiedSigner)
+	adminHandler := handlers.NewAdminHandler()
+	v1.GET("/admin/users/:id/signer", adminHandler.SignerStatus, requireUser, auth.RequireAdmin)
+	v1.POST("/admin/users/:id/signer", adminHandler.RegisterSigner, requireUser, auth.RequireAdmin)
+	v1.DELETE("/admin/users/:id/signer", adminHandler.UnregisterSigner, requireUser, auth.RequireAdmin)
+	v1.POST("/admin/users/:id/suspend", adminHandler.SuspendUser, requireUser, auth.RequireAdmin)
+	v1.POST("/admin/users/:id/reinstate", adminHandler.ReinstateUser, requireUser, auth.RequireAdmin)
+	v1.DELETE("/admin/users/:id", adminHandler.DeleteUser, requireUser, auth.RequireAdmin)
 
 	// Start server with graceful shutdown
 	go func() {
@@ -124,6 +301,7 @@ func main() {
 	defer cancel()
 
 	log.Println("Shutting down server...")
+	stopWorker()
 	if err := e.Shutdown(ctx); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
@@ -0,0 +1,116 @@
+// Command reconcile audits signature_metadata against the InklessRegistry
+// contract and reports every row the two disagree on: mock tx hashes and
+// simulated-chain anchors, missing anchors, anchors under the wrong signer or
+// device, and rows revoked on-chain but still active in Postgres. With --fix it re-queues signatures whose
+// anchor never reached the chain and marks the rest; a running API's worker
+// picks the re-queued jobs up on its next poll. It audits one network at a
+// time, the primary unless --network names another.
+//
+//	go run ./cmd/reconcile --format csv --out reconcile.csv
+//	go run ./cmd/reconcile --fix
+//	go run ./cmd/reconcile --network polygon
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/config"
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/ledger"
+	"github.com/inkless/backend/internal/reconcile"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "re-queue missing anchors and mark mismatched rows")
+	format := flag.String("format", reconcile.FormatJSON, "report format: json or csv")
+	out := flag.String("out", "", "write the report to this file instead of stdout")
+	networkName := flag.String("network", "", "ledger network to audit; defaults to the primary")
+	flag.Parse()
+
+	if *format != reconcile.FormatJSON && *format != reconcile.FormatCSV {
+		log.Fatalf("Unknown --format %q: use json or csv", *format)
+	}
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Connect to database; query logging would end up in a report on stdout
+	if err := db.Connect(cfg); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	db.DB.Logger = logger.Default.LogMode(logger.Silent)
+
+	// The audit is meaningless without a ledger to compare against
+	maxFee, err := ledger.ParseGwei(cfg.LedgerMaxFeeGwei)
+	if err != nil {
+		log.Fatalf("Invalid LEDGER_MAX_FEE_GWEI: %v", err)
+	}
+	maxPriorityFee, err := ledger.ParseGwei(cfg.LedgerMaxPriorityFeeGwei)
+	if err != nil {
+		log.Fatalf("Invalid LEDGER_MAX_PRIORITY_FEE_GWEI: %v", err)
+	}
+	fees := ledger.FeeConfig{
+		GasLimitMargin:       cfg.LedgerGasLimitMargin,
+		MaxGasLimit:          uint64(cfg.LedgerMaxGasLimit),
+		MaxFeePerGas:         maxFee,
+		MaxPriorityFeePerGas: maxPriorityFee,
+	}
+	networks, err := ledger.ConfiguredNetworks(cfg)
+	if err != nil {
+		log.Fatalf("Invalid ledger configuration: %v", err)
+	}
+	if err := ledger.Configure(networks); err != nil {
+		log.Fatalf("Invalid ledger configuration: %v", err)
+	}
+	// Rows re-queued in fix mode are routed like new signatures
+	if err := anchoring.SetRoutes(cfg.LedgerDefaultNetworks, cfg.LedgerCategoryNetworks); err != nil {
+		log.Fatalf("Invalid ledger network routes: %v", err)
+	}
+	if *networkName == "" {
+		*networkName = ledger.PrimaryNetwork()
+	}
+	var network *ledger.NetworkConfig
+	for i := range networks {
+		if networks[i].Name == *networkName {
+			network = &networks[i]
+		}
+	}
+	if network == nil || network.Backend != ledger.BackendBesu {
+		log.Fatal("No ledger network to audit: set BESU_NODE_URL and CONTRACT_ADDRESS, or LEDGER_NETWORKS")
+	}
+	if err := ledger.Connect(*network, fees); err != nil {
+		log.Fatalf("Ledger connection failed: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	findings, runErr := reconcile.New(db.DB, reconcile.Options{Fix: *fix, Network: network.Name}).Run(ctx)
+
+	// Report what was found even if the run stopped early
+	if err := reconcile.WriteReport(w, *format, findings); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	log.Printf("Reconciliation found %d issues", len(findings))
+	if runErr != nil {
+		log.Fatalf("Reconciliation stopped early: %v", runErr)
+	}
+}
@@ -0,0 +1,31 @@
+package anchoring
+
+import (
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"gorm.io/gorm"
+)
+
+// AssignAccounts records the signer account derived for each user that has
+// none yet, such as users created before signer accounts were configured.
+// It returns how many users it updated.
+func AssignAccounts(database *gorm.DB) (int, error) {
+	if !ledger.AccountsEnabled() {
+		return 0, nil
+	}
+
+	var users []models.User
+	if err := database.Select("id", "d_id_address").Where("chain_address = '' OR chain_address IS NULL").Find(&users).Error; err != nil {
+		return 0, err
+	}
+	for _, user := range users {
+		address, err := ledger.AccountAddress(user.DIDAddress)
+		if err != nil {
+			return 0, err
+		}
+		if err := database.Model(&models.User{}).Where("id = ?", user.ID).Update("chain_address", address).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(users), nil
+}
@@ -0,0 +1,216 @@
+package anchoring
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"github.com/inkless/backend/internal/merkle"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// batchMagic prefixes the descriptor anchored with a batch root
+const batchMagic = "INKLESS-BATCH-V1"
+
+// LeafHash is the Merkle leaf committed for one signature:
+// keccak256(0x00 || docHash || keccak256(signerDID) || keccak256(payload)).
+// Anyone holding the document hash, the signer's DID and the anchored
+// signature bytes can recompute it.
+func LeafHash(docHash, signerDID string, payload []byte) common.Hash {
+	return merkle.Leaf(
+		common.HexToHash(docHash).Bytes(),
+		ledger.SignerID(signerDID).Bytes(),
+		crypto.Keccak256(payload),
+	)
+}
+
+// batch folds each network's queued signature jobs into one batch job once
+// BatchMaxLeaves are waiting or the oldest has waited BatchMaxWait. Each
+// signature gets its leaf and inclusion proof; only the root goes on-chain.
+// Redundant copies are anchored one by one, so the row's proof always
+// belongs to its own network's root.
+func (w *Worker) batch(ctx context.Context) {
+	if !w.opts.Batching {
+		return
+	}
+
+	var networks []string
+	if err := w.db.WithContext(ctx).
+		Model(&models.AnchorJob{}).
+		Where("kind = ? AND status = ? AND NOT redundant", JobKindSignature, JobQueued).
+		Distinct("network").
+		Pluck("network", &networks).Error; err != nil {
+		log.Printf("[Anchoring] Failed to list queued networks: %v", err)
+		return
+	}
+
+	for _, network := range networks {
+		for ctx.Err() == nil {
+			formed, err := w.formBatch(ctx, network)
+			if err != nil {
+				log.Printf("[Anchoring] Failed to form batch: %v", err)
+				return
+			}
+			if !formed {
+				break
+			}
+		}
+	}
+}
+
+// formBatch builds at most one batch for network and reports whether it did
+func (w *Worker) formBatch(ctx context.Context, network string) (bool, error) {
+	formed := false
+
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var jobs []models.AnchorJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("kind = ? AND status = ? AND network = ? AND NOT redundant AND next_attempt_at <= ?", JobKindSignature, JobQueued, network, time.Now()).
+			Order("created_at").
+			Limit(w.opts.BatchMaxLeaves).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		if len(jobs) < w.opts.BatchMaxLeaves && time.Since(jobs[0].CreatedAt) < w.opts.BatchMaxWait {
+			return nil
+		}
+
+		leaves := make([]common.Hash, len(jobs))
+		for i, job := range jobs {
+			leaves[i] = LeafHash(job.DocHash, job.SignerDID, job.Payload)
+		}
+		tree := merkle.Build(leaves)
+		root := tree.Root().Hex()
+
+		batchJob := models.AnchorJob{
+			Kind:       JobKindBatch,
+			DocHash:    root,
+			Payload:    batchDescriptor(len(jobs)),
+			HardwareID: "inkless-merkle-batch",
+			Status:     JobQueued,
+			Network:    network,
+		}
+		if err := tx.Create(&batchJob).Error; err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.ID
+
+			index := i
+			proof, _ := json.Marshal(tree.Proof(i))
+			proofJSON := string(proof)
+			if err := tx.Model(&models.SignatureMetadata{}).
+				Where("id = ?", job.SignatureID).
+				Updates(models.SignatureMetadata{
+					MerkleRoot:  root,
+					MerkleLeaf:  leaves[i].Hex(),
+					MerkleIndex: &index,
+					MerkleProof: &proofJSON,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.AnchorJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": JobBatched, "batch_job_id": batchJob.ID}).Error; err != nil {
+			return err
+		}
+
+		log.Printf("[Anchoring] Batched %d signatures under root %s", len(jobs), root)
+		formed = true
+		return nil
+	})
+
+	return formed, err
+}
+
+// releaseBatch hands the members of a failed batch back to the queue to be
+// batched again after a backoff, clearing the proofs that led to its root.
+// Each failed batch counts as an attempt for its members, and those out of
+// attempts fail with it, taking sigUpdates.
+func (w *Worker) releaseBatch(tx *gorm.DB, batch *models.AnchorJob, lastErr string, sigUpdates map[string]interface{}) error {
+	var members []models.AnchorJob
+	if err := tx.Where("batch_job_id = ? AND status = ?", batch.ID, JobBatched).Find(&members).Error; err != nil {
+		return err
+	}
+
+	var retryJobs, retrySigs, failJobs, failSigs []uuid.UUID
+	for _, m := range members {
+		if m.Attempts+1 >= w.opts.MaxAttempts {
+			failJobs, failSigs = append(failJobs, m.ID), append(failSigs, *m.SignatureID)
+		} else {
+			retryJobs, retrySigs = append(retryJobs, m.ID), append(retrySigs, *m.SignatureID)
+		}
+	}
+
+	if len(failJobs) > 0 {
+		if err := tx.Model(&models.AnchorJob{}).Where("id IN ?", failJobs).Updates(map[string]interface{}{
+			"status":     JobFailed,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": lastErr,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SignatureMetadata{}).
+			Where("id IN ? AND status <> ?", failSigs, SignatureRevoked).
+			Updates(sigUpdates).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, m := range members {
+		if m.Attempts+1 >= w.opts.MaxAttempts {
+			continue
+		}
+		if err := tx.Model(&models.AnchorJob{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+			"status":          JobQueued,
+			"batch_job_id":    nil,
+			"attempts":        m.Attempts + 1,
+			"next_attempt_at": time.Now().Add(w.backoff(m.Attempts + 1)),
+			"last_error":      lastErr,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	if len(retrySigs) > 0 {
+		if err := tx.Model(&models.SignatureMetadata{}).
+			Where("id IN ? AND status <> ?", retrySigs, SignatureRevoked).
+			Updates(map[string]interface{}{
+				"status":          SignaturePending,
+				"ledger_tx_hash":  nil,
+				"merkle_root":     "",
+				"merkle_leaf":     "",
+				"merkle_index":    nil,
+				"merkle_proof":    nil,
+				"block_number":    nil,
+				"block_hash":      "",
+				"block_timestamp": nil,
+				"gas_used":        nil,
+				"confirmations":   0,
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[Anchoring] Batch %s failed; %d signatures requeued, %d out of attempts", batch.DocHash, len(retryJobs), len(failJobs))
+	return nil
+}
+
+// batchDescriptor is stored on-chain alongside a root: magic || u32 leaf count
+func batchDescriptor(leaves int) []byte {
+	return binary.BigEndian.AppendUint32([]byte(batchMagic), uint32(leaves))
+}
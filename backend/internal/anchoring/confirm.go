@@ -0,0 +1,195 @@
+package anchoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"gorm.io/gorm"
+)
+
+// watchBatchSize bounds receipts checked per poll
+const watchBatchSize = 100
+
+// watch checks the receipt of every submitted job. A tx is anchored once
+// Confirmations blocks sit on top of it; a revert fails it; a tx the node no
+// longer knows about (dropped, or reorged out and not re-broadcast) is
+// resubmitted after DropTimeout.
+func (w *Worker) watch(ctx context.Context) {
+	if len(ledger.ConnectedNetworks()) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	var jobs []models.AnchorJob
+	if err := w.db.WithContext(ctx).
+		Where("status = ? AND tx_hash IS NOT NULL AND network IN ?", JobSubmitted, reachableNetworks()).
+		Order("submitted_at").
+		Limit(watchBatchSize).
+		Find(&jobs).Error; err != nil {
+		log.Printf("[Anchoring] Failed to load submitted jobs: %v", err)
+		return
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		w.checkReceipt(ctx, &jobs[i])
+	}
+}
+
+// checkReceipt advances one submitted job
+func (w *Worker) checkReceipt(ctx context.Context, job *models.AnchorJob) {
+	rctx, cancel := context.WithTimeout(ctx, submitTimeout)
+	defer cancel()
+
+	receipt, err := ledger.Network(job.Network).TransactionReceipt(rctx, *job.TxHash)
+
+	switch {
+	case errors.Is(err, ledger.ErrTxNotFound):
+		// A gas-bumped original can still win the race for its nonce
+		if w.adoptMinedPrior(rctx, job) {
+			return
+		}
+		if job.SubmittedAt != nil && time.Since(*job.SubmittedAt) < w.opts.DropTimeout {
+			return
+		}
+		log.Printf("[Anchoring] %s for %s was dropped, resubmitting", *job.TxHash, job.DocHash)
+		w.clearBlock(job)
+		w.retry(job, job.Attempts, fmt.Sprintf("transaction %s dropped", *job.TxHash))
+
+	case err != nil:
+		log.Printf("[Anchoring] Failed to check receipt for %s: %v", *job.TxHash, err)
+
+	case receipt.Pending:
+		// Not mined yet, or its block was just reorged away
+		if job.BlockHash != "" {
+			log.Printf("[Anchoring] Block %s holding %s was reorged out", job.BlockHash, *job.TxHash)
+			w.clearBlock(job)
+			return
+		}
+		if job.SubmittedAt != nil && time.Since(*job.SubmittedAt) >= w.opts.StuckTimeout {
+			w.bumpGas(rctx, job)
+		}
+
+	case receipt.Reverted:
+		log.Printf("[Anchoring] %s for %s reverted in block %d", *job.TxHash, job.DocHash, receipt.BlockNumber)
+		w.fail(job, job.Attempts, fmt.Sprintf("transaction reverted in block %d", receipt.BlockNumber), blockUpdates(receipt))
+
+	default:
+		if job.BlockHash != "" && job.BlockHash != receipt.BlockHash {
+			log.Printf("[Anchoring] %s moved from block %s to %s after a reorg", *job.TxHash, job.BlockHash, receipt.BlockHash)
+		}
+
+		updates := blockUpdates(receipt)
+		if receipt.Confirmations < uint64(w.opts.Confirmations) {
+			w.db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Model(job).Update("block_hash", receipt.BlockHash).Error; err != nil {
+					return err
+				}
+				return signaturesOf(tx, job).Updates(updates).Error
+			})
+			return
+		}
+
+		updates["status"] = SignatureAnchored
+		w.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(job).Updates(map[string]interface{}{"status": JobDone, "block_hash": receipt.BlockHash, "last_error": nil}).Error; err != nil {
+				return err
+			}
+			return signaturesOf(tx, job).Updates(updates).Error
+		})
+		if IsSignerJob(job) || job.Kind == JobKindRevoke {
+			w.recordOutcome(job, true, *job.TxHash, "")
+			log.Printf("[Anchoring] Confirmed %s of %s in block %d", job.Kind, job.DocHash, receipt.BlockNumber)
+			return
+		}
+		log.Printf("[Anchoring] Anchored %s in block %d (%d confirmations)", job.DocHash, receipt.BlockNumber, receipt.Confirmations)
+	}
+}
+
+// bumpGas replaces a transaction stuck in the mempool with a higher gas price
+// at the same nonce. The old hash is kept in PriorTxHashes in case it is
+// mined after all.
+func (w *Worker) bumpGas(ctx context.Context, job *models.AnchorJob) {
+	newHash, err := ledger.Network(job.Network).ReplaceTransaction(ctx, *job.TxHash, w.opts.GasBumpPercent)
+	if err != nil {
+		log.Printf("[Anchoring] Failed to replace stuck %s: %v", *job.TxHash, err)
+		return
+	}
+	log.Printf("[Anchoring] Replaced stuck %s with %s (+%d%% gas)", *job.TxHash, newHash, w.opts.GasBumpPercent)
+
+	prior := *job.TxHash
+	if job.PriorTxHashes != "" {
+		prior = job.PriorTxHashes + "," + prior
+	}
+	w.switchTx(job, newHash, prior, time.Now())
+}
+
+// adoptMinedPrior points the job back at a replaced tx that was mined instead
+// of its replacement. It reports whether one was found.
+func (w *Worker) adoptMinedPrior(ctx context.Context, job *models.AnchorJob) bool {
+	if job.PriorTxHashes == "" {
+		return false
+	}
+	for _, hash := range strings.Split(job.PriorTxHashes, ",") {
+		receipt, err := ledger.Network(job.Network).TransactionReceipt(ctx, hash)
+		if err != nil || receipt.Pending {
+			continue
+		}
+		log.Printf("[Anchoring] Replaced tx %s was mined instead of %s", hash, *job.TxHash)
+		w.switchTx(job, hash, "", *job.SubmittedAt)
+		return true
+	}
+	return false
+}
+
+// switchTx makes hash the tx the job and its signature track
+func (w *Worker) switchTx(job *models.AnchorJob, hash, prior string, submittedAt time.Time) {
+	w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).Updates(map[string]interface{}{
+			"tx_hash":         hash,
+			"prior_tx_hashes": prior,
+			"submitted_at":    submittedAt,
+		}).Error; err != nil {
+			return err
+		}
+		if err := revocationOf(tx, job).Update("revocation_tx_hash", hash).Error; err != nil {
+			return err
+		}
+		return signaturesOf(tx, job).
+			Update("ledger_tx_hash", hash).Error
+	})
+}
+
+// blockUpdates are the signature columns recorded from a mined receipt
+func blockUpdates(receipt *ledger.Receipt) map[string]interface{} {
+	return map[string]interface{}{
+		"block_number":    receipt.BlockNumber,
+		"block_hash":      receipt.BlockHash,
+		"block_timestamp": receipt.BlockTime,
+		"gas_used":        receipt.GasUsed,
+		"confirmations":   receipt.Confirmations,
+	}
+}
+
+// clearBlock forgets block details that no longer hold after a reorg or drop
+func (w *Worker) clearBlock(job *models.AnchorJob) {
+	w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).Update("block_hash", "").Error; err != nil {
+			return err
+		}
+		return signaturesOf(tx, job).Updates(map[string]interface{}{
+			"block_number":    nil,
+			"block_hash":      "",
+			"block_timestamp": nil,
+			"gas_used":        nil,
+			"confirmations":   0,
+		}).Error
+	})
+}
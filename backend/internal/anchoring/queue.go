@@ -0,0 +1,167 @@
+// Package anchoring submits signatures to the ledger in the background.
+// Handlers record a signature as "pending" and enqueue an AnchorJob in the
+// same transaction; a Worker claims due jobs, retries ledger failures with
+// exponential backoff, then watches the receipt and moves the signature to
+// "anchored" once it is buried under enough blocks, or to "failed".
+// Changes to a user's verified signer status and revocations of anchored
+// signatures ride the same queue.
+package anchoring
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/db/models"
+	"gorm.io/gorm"
+)
+
+// Job states
+const (
+	JobQueued     = "queued"
+	JobProcessing = "processing"
+	JobSubmitted  = "submitted"
+	JobBatched    = "batched" // Folded into a batch job, which tracks the ledger tx
+	JobDone       = "done"
+	JobFailed     = "failed"
+)
+
+// Job kinds
+const (
+	JobKindSignature    = "signature"
+	JobKindBatch        = "batch"
+	JobKindAddSigner    = "add_signer"    // addVerifiedSigner for a user's signer account
+	JobKindRemoveSigner = "remove_signer" // removeVerifiedSigner for a user's signer account
+	JobKindRevoke       = "revoke"        // revokeSignature for a revoked signature's record
+)
+
+// Signature states driven by the queue
+const (
+	SignaturePending   = "pending"   // Queued, not yet accepted by the node
+	SignatureSubmitted = "submitted" // Sent, waiting for confirmations
+	SignatureAnchored  = "anchored"  // Confirmed to the configured depth
+	SignatureFailed    = "failed"
+	SignatureRevoked   = "revoked" // Set by the revoke API; the queue leaves these rows alone
+)
+
+// wake nudges the worker so a fresh job doesn't wait for the next poll
+var wake = make(chan struct{}, 1)
+
+// Enqueue schedules sig, made by signerDID, for anchoring on each network
+// its category is routed to, and records the first as the row's network.
+// payload is the exact byte string the ledger should commit to. Call it
+// inside the transaction that creates sig, then Notify once that transaction
+// has committed. It returns the job the row tracks.
+func Enqueue(tx *gorm.DB, sig *models.SignatureMetadata, signerDID string, payload []byte) (*models.AnchorJob, error) {
+	networks := NetworksFor(sig.DocumentCategory)
+	jobs := make([]models.AnchorJob, len(networks))
+	for i, network := range networks {
+		jobs[i] = models.AnchorJob{
+			SignatureID:   &sig.ID,
+			Kind:          JobKindSignature,
+			DocHash:       sig.DocHash,
+			SignerDID:     signerDID,
+			Payload:       payload,
+			HardwareID:    sig.HardwareID,
+			Status:        JobQueued,
+			NextAttemptAt: time.Now(),
+			Network:       network,
+			Redundant:     i > 0,
+		}
+	}
+	if err := tx.Create(&jobs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(sig).Update("ledger_network", networks[0]).Error; err != nil {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+// Notify wakes the worker early. It never blocks.
+func Notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// IsFinal reports whether a signature status will no longer change on its own
+func IsFinal(status string) bool {
+	return status != SignaturePending && status != SignatureSubmitted
+}
+
+// ExpectsChainRecord reports whether a row claims it was anchored
+func ExpectsChainRecord(sig *models.SignatureMetadata) bool {
+	switch sig.Status {
+	case SignatureAnchored, "verified":
+		return true
+	case SignatureRevoked:
+		return sig.BlockNumber != nil || sig.RevocationTxHash != nil
+	}
+	return false
+}
+
+// Requeue schedules sig to be anchored afresh, for a row whose anchor never
+// reached the chain. The job the row tracks is reset, or jobs are created
+// from payload for rows that predate the queue, and every chain field the
+// last attempt left on the row is cleared. Redundant copies are left alone.
+func Requeue(tx *gorm.DB, sig *models.SignatureMetadata, signerDID string, payload []byte) error {
+	var job models.AnchorJob
+	err := tx.Where("signature_id = ? AND kind = ? AND NOT redundant", sig.ID, JobKindSignature).Limit(1).Find(&job).Error
+	if err != nil {
+		return err
+	}
+	if job.ID == uuid.Nil {
+		if _, err := Enqueue(tx, sig, signerDID, payload); err != nil {
+			return err
+		}
+	} else if err := tx.Model(&job).Updates(map[string]interface{}{
+		"kind":            JobKindSignature,
+		"batch_job_id":    nil,
+		"status":          JobQueued,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"locked_at":       nil,
+		"last_error":      nil,
+		"tx_hash":         nil,
+		"prior_tx_hashes": "",
+		"block_hash":      "",
+		"submitted_at":    nil,
+	}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(sig).Updates(map[string]interface{}{
+		"status":          SignaturePending,
+		"ledger_tx_hash":  nil,
+		"merkle_root":     "",
+		"merkle_leaf":     "",
+		"merkle_index":    nil,
+		"merkle_proof":    nil,
+		"block_number":    nil,
+		"block_hash":      "",
+		"block_timestamp": nil,
+		"gas_used":        nil,
+		"confirmations":   0,
+	}).Error
+}
+
+// signaturesOf scopes a query to the signatures a job anchors: its own, or
+// every member of a batch. Revoked signatures are skipped so a batch root
+// confirming later can't bring them back, and a redundant copy matches
+// nothing: the row follows its own network's job. Nor do signer changes
+// and revocations.
+func signaturesOf(tx *gorm.DB, job *models.AnchorJob) *gorm.DB {
+	q := tx.Model(&models.SignatureMetadata{}).Where("status <> ?", SignatureRevoked)
+	if job.Redundant || IsSignerJob(job) || job.Kind == JobKindRevoke {
+		return q.Where("1 = 0")
+	}
+	if job.Kind == JobKindBatch {
+		members := tx.Session(&gorm.Session{NewDB: true}).
+			Model(&models.AnchorJob{}).
+			Select("signature_id").
+			Where("batch_job_id = ?", job.ID)
+		return q.Where("id IN (?)", members)
+	}
+	return q.Where("id = ?", job.SignatureID)
+}
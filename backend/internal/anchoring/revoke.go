@@ -0,0 +1,120 @@
+package anchoring
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/inkless/backend/internal/db/models"
+	"gorm.io/gorm"
+)
+
+// EnqueueRevocation schedules revoking sig's on-chain record on its network,
+// if it has one, and each redundant copy anchored on another network.
+// Batched signatures share their root's record, which can't be revoked for
+// one signer. Call it inside the transaction that marks sig revoked, before
+// it does, then Notify once that transaction has committed. It returns the
+// jobs it created, the row's own network's first.
+func EnqueueRevocation(tx *gorm.DB, sig *models.SignatureMetadata, signerDID string) ([]models.AnchorJob, error) {
+	var jobs []models.AnchorJob
+	if sig.MerkleRoot == "" && ExpectsChainRecord(sig) {
+		jobs = append(jobs, revocationJob(sig, signerDID, sig.LedgerNetwork, false))
+	}
+
+	var copies []models.AnchorJob
+	if err := tx.Where("signature_id = ? AND kind = ? AND redundant AND status = ?", sig.ID, JobKindSignature, JobDone).
+		Order("network").
+		Find(&copies).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range copies {
+		jobs = append(jobs, revocationJob(sig, signerDID, c.Network, true))
+	}
+
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	if err := tx.Create(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// revocationJob is a queued revocation of sig's record on network
+func revocationJob(sig *models.SignatureMetadata, signerDID, network string, redundant bool) models.AnchorJob {
+	return models.AnchorJob{
+		SignatureID:   &sig.ID,
+		Kind:          JobKindRevoke,
+		DocHash:       sig.DocHash,
+		SignerDID:     signerDID,
+		Payload:       []byte{},
+		Status:        JobQueued,
+		NextAttemptAt: time.Now(),
+		Network:       network,
+		Redundant:     redundant,
+	}
+}
+
+// revocationOf scopes a query to the signature whose revocation job tracks
+// its revocation tx; only the row's own network's revocation does
+func revocationOf(tx *gorm.DB, job *models.AnchorJob) *gorm.DB {
+	q := tx.Model(&models.SignatureMetadata{})
+	if job.Kind != JobKindRevoke || job.Redundant {
+		return q.Where("1 = 0")
+	}
+	return q.Where("id = ?", job.SignatureID)
+}
+
+// ownsLegacyRecord reports whether a revocation may fall back to the record
+// under the bare docHash: only the document's first signer owns it, and
+// copies on secondary networks postdate per-signer anchoring
+func (w *Worker) ownsLegacyRecord(job *models.AnchorJob) bool {
+	if job.Redundant || job.SignatureID == nil {
+		return false
+	}
+	var first models.SignatureMetadata
+	w.db.Select("id").Where("doc_hash = ?", job.DocHash).Order("created_at asc").First(&first)
+	return first.ID == *job.SignatureID
+}
+
+// recordRevocation writes the audit log of a revocation's outcome. txHash is
+// empty when there was no on-chain record to revoke.
+func (w *Worker) recordRevocation(job *models.AnchorJob, outcome, txHash, lastErr string) {
+	var sig models.SignatureMetadata
+	if err := w.db.Select("id", "signer_id").Where("id = ?", job.SignatureID).First(&sig).Error; err != nil {
+		log.Printf("[Anchoring] No signature for revocation %s: %v", job.ID, err)
+		return
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"jobId":       job.ID,
+		"signatureId": sig.ID,
+		"docHash":     job.DocHash,
+		"network":     networkOf(job),
+		"redundant":   job.Redundant,
+		"txHash":      txHash,
+		"error":       lastErr,
+	})
+	metadataStr := string(metadata)
+	w.db.Create(&models.AuditLog{
+		UserID:     sig.SignerID,
+		ActionType: outcome,
+		Metadata:   &metadataStr,
+		Timestamp:  time.Now(),
+	})
+}
+
+// recordOutcome audits how a signer change or revocation ended; anchors are
+// tracked on their signature rows instead
+func (w *Worker) recordOutcome(job *models.AnchorJob, confirmed bool, txHash, lastErr string) {
+	switch {
+	case IsSignerJob(job) && confirmed:
+		w.recordSignerChange(job, "signer_registry_confirmed", txHash, lastErr)
+	case IsSignerJob(job):
+		w.recordSignerChange(job, "signer_registry_failed", txHash, lastErr)
+	case job.Kind == JobKindRevoke && confirmed:
+		w.recordRevocation(job, "signature_revoke_confirmed", txHash, lastErr)
+	case job.Kind == JobKindRevoke:
+		w.recordRevocation(job, "signature_revoke_failed", txHash, lastErr)
+	}
+}
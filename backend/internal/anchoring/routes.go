@@ -0,0 +1,88 @@
+package anchoring
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+)
+
+// Routes pick the ledger networks a signature is anchored on
+var (
+	routesMu         sync.RWMutex
+	defaultNetworks  []string
+	categoryNetworks map[string][]string
+)
+
+// SetRoutes configures which networks signatures are anchored on: those
+// listed for their document category, else defaults, else the primary
+// network. The first network of a route is the one the signature row tracks;
+// any others hold redundant copies. Every name must be a configured network.
+func SetRoutes(defaults []string, byCategory map[string][]string) error {
+	validate := func(route []string) error {
+		seen := make(map[string]bool, len(route))
+		for _, name := range route {
+			if !ledger.IsNetwork(name) {
+				return fmt.Errorf("unknown ledger network %q", name)
+			}
+			if seen[name] {
+				return fmt.Errorf("ledger network %q is listed twice", name)
+			}
+			seen[name] = true
+		}
+		return nil
+	}
+
+	if err := validate(defaults); err != nil {
+		return err
+	}
+	routes := make(map[string][]string, len(byCategory))
+	for category, route := range byCategory {
+		if err := validate(route); err != nil {
+			return fmt.Errorf("category %s: %w", category, err)
+		}
+		routes[strings.ToLower(category)] = route
+	}
+
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	defaultNetworks = defaults
+	categoryNetworks = routes
+	return nil
+}
+
+// NetworksFor returns the networks a signature in category is anchored on,
+// the one its row tracks first. With no networks configured it is the
+// primary network, named "", whichever that turns out to be.
+func NetworksFor(category string) []string {
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+	if route := categoryNetworks[strings.ToLower(category)]; len(route) > 0 {
+		return append([]string(nil), route...)
+	}
+	if len(defaultNetworks) > 0 {
+		return append([]string(nil), defaultNetworks...)
+	}
+	return []string{ledger.PrimaryNetwork()}
+}
+
+// networkOf names the network a job anchors on; jobs queued before networks
+// were named belong to the primary
+func networkOf(job *models.AnchorJob) string {
+	if job.Network != "" {
+		return job.Network
+	}
+	return ledger.PrimaryNetwork()
+}
+
+// reachableNetworks lists the job networks with a connected ledger, including
+// the empty name while the primary is connected
+func reachableNetworks() []string {
+	names := ledger.ConnectedNetworks()
+	if ledger.IsConnected() {
+		names = append(names, "")
+	}
+	return names
+}
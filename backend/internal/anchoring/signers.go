@@ -0,0 +1,135 @@
+package anchoring
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"gorm.io/gorm"
+)
+
+// Reasons a user's verified signer status changes, recorded in audit logs
+const (
+	SignerReasonVerified   = "vnin_verified" // Identity verification succeeded
+	SignerReasonSuspended  = "suspended"
+	SignerReasonDeleted    = "deleted"
+	SignerReasonReinstated = "reinstated"
+	SignerReasonAdmin      = "admin" // Set or cleared by hand through the admin API
+)
+
+// ErrNoSignerAccount is returned for users without a signer account, which
+// happens while signer accounts aren't configured
+var ErrNoSignerAccount = errors.New("user has no signer account")
+
+// errChangePending holds a signer change back until an earlier change to the
+// same account on the same network is settled, so they land in order
+var errChangePending = errors.New("an earlier change to this signer is still pending")
+
+// IsSignerJob reports whether a job changes a verified signer rather than anchoring
+func IsSignerJob(job *models.AnchorJob) bool {
+	return job.Kind == JobKindAddSigner || job.Kind == JobKindRemoveSigner
+}
+
+// SetSigner schedules adding user's signer account to the contract's
+// verified signers on every configured network, or removing it. A queued
+// change the other way is superseded, and a network whose latest change
+// already went this way is skipped. Call it inside the transaction that
+// changes the user, then Notify once it has committed. It returns the jobs
+// it created.
+func SetSigner(tx *gorm.DB, user *models.User, verified bool) ([]models.AnchorJob, error) {
+	if user.ChainAddress == "" {
+		return nil, ErrNoSignerAccount
+	}
+	kind, opposite := JobKindRemoveSigner, JobKindAddSigner
+	if verified {
+		kind, opposite = JobKindAddSigner, JobKindRemoveSigner
+	}
+
+	if err := tx.Model(&models.AnchorJob{}).
+		Where("kind = ? AND doc_hash = ? AND status = ?", opposite, user.ChainAddress, JobQueued).
+		Updates(map[string]interface{}{"status": JobFailed, "last_error": "superseded"}).Error; err != nil {
+		return nil, err
+	}
+
+	var jobs []models.AnchorJob
+	for _, n := range ledger.Networks() {
+		var latest models.AnchorJob
+		if err := tx.Where("kind IN ? AND doc_hash = ? AND network = ? AND status <> ?",
+			[]string{JobKindAddSigner, JobKindRemoveSigner}, user.ChainAddress, n.Name, JobFailed).
+			Order("created_at DESC").
+			Limit(1).
+			Find(&latest).Error; err != nil {
+			return nil, err
+		}
+		if latest.Kind == kind {
+			continue
+		}
+		jobs = append(jobs, models.AnchorJob{
+			Kind:          kind,
+			DocHash:       user.ChainAddress,
+			SignerDID:     user.DIDAddress,
+			Payload:       []byte{},
+			Status:        JobQueued,
+			NextAttemptAt: time.Now(),
+			Network:       n.Name,
+		})
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	if err := tx.Create(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// SignerJobs returns every change queued for a signer account, newest first
+func SignerJobs(database *gorm.DB, address string) ([]models.AnchorJob, error) {
+	var jobs []models.AnchorJob
+	err := database.
+		Where("kind IN ? AND doc_hash = ?", []string{JobKindAddSigner, JobKindRemoveSigner}, address).
+		Order("created_at DESC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// changePending reports whether a change to job's account queued before it
+// on the same network hasn't settled yet
+func (w *Worker) changePending(job *models.AnchorJob) (bool, error) {
+	var count int64
+	err := w.db.Model(&models.AnchorJob{}).
+		Where("kind IN ? AND doc_hash = ? AND network = ? AND id <> ? AND created_at < ? AND status IN ?",
+			[]string{JobKindAddSigner, JobKindRemoveSigner}, job.DocHash, job.Network, job.ID, job.CreatedAt,
+			[]string{JobQueued, JobProcessing, JobSubmitted}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// recordSignerChange writes the audit log of a signer change's outcome.
+// txHash is empty when the contract was already in the requested state.
+func (w *Worker) recordSignerChange(job *models.AnchorJob, outcome, txHash, lastErr string) {
+	var user models.User
+	if err := w.db.Select("id").Where("d_id_address = ?", job.SignerDID).First(&user).Error; err != nil {
+		log.Printf("[Anchoring] No user for signer change %s: %v", job.ID, err)
+		return
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"jobId":   job.ID,
+		"kind":    job.Kind,
+		"address": job.DocHash,
+		"network": networkOf(job),
+		"txHash":  txHash,
+		"error":   lastErr,
+	})
+	metadataStr := string(metadata)
+	w.db.Create(&models.AuditLog{
+		UserID:     user.ID,
+		ActionType: outcome,
+		Metadata:   &metadataStr,
+		Timestamp:  time.Now(),
+	})
+}
@@ -0,0 +1,405 @@
+package anchoring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Options tune the worker
+type Options struct {
+	PollInterval time.Duration // How often to look for due jobs
+	MaxAttempts  int           // Submissions before a job is marked failed
+	BaseBackoff  time.Duration // Delay after the first failure, doubled each retry
+	MaxBackoff   time.Duration // Upper bound on the retry delay
+	BatchSize    int           // Jobs claimed per poll
+	LockTimeout  time.Duration // A processing job older than this is presumed orphaned
+
+	Confirmations int           // Blocks on top of the anchor before it counts as anchored
+	DropTimeout   time.Duration // How long a tx may be unknown to the node before it is resubmitted
+
+	StuckTimeout   time.Duration // How long a tx may sit in the mempool before its gas price is bumped
+	GasBumpPercent int           // Gas price increase for a replacement (nodes require at least 10)
+
+	Batching       bool          // Anchor Merkle roots of batches instead of each signature
+	BatchMaxLeaves int           // Signatures per batch
+	BatchMaxWait   time.Duration // Longest a signature waits for its batch to fill
+}
+
+// submitTimeout bounds a single ledger submission
+const submitTimeout = 30 * time.Second
+
+// Worker drains the anchor queue
+type Worker struct {
+	db   *gorm.DB
+	opts Options
+}
+
+// NewWorker creates a worker, filling in defaults for unset options
+func NewWorker(database *gorm.DB, opts Options) *Worker {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 8
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = 10 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 30 * time.Minute
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 10
+	}
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = 5 * time.Minute
+	}
+	if opts.Confirmations <= 0 {
+		opts.Confirmations = 12
+	}
+	if opts.DropTimeout <= 0 {
+		opts.DropTimeout = 10 * time.Minute
+	}
+	if opts.StuckTimeout <= 0 {
+		opts.StuckTimeout = 5 * time.Minute
+	}
+	if opts.GasBumpPercent < 10 {
+		opts.GasBumpPercent = 20
+	}
+	if opts.BatchMaxLeaves <= 0 {
+		opts.BatchMaxLeaves = 256
+	}
+	if opts.BatchMaxWait <= 0 {
+		opts.BatchMaxWait = time.Minute
+	}
+	return &Worker{db: database, opts: opts}
+}
+
+// Run processes due jobs until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	log.Printf("[Anchoring] Worker started (poll every %s, max %d attempts, %d confirmations)", w.opts.PollInterval, w.opts.MaxAttempts, w.opts.Confirmations)
+	if w.opts.Batching {
+		log.Printf("[Anchoring] Batching up to %d signatures or %s per Merkle root", w.opts.BatchMaxLeaves, w.opts.BatchMaxWait)
+	}
+	if len(ledger.ConnectedNetworks()) == 0 {
+		log.Println("[Anchoring] No ledger configured; jobs stay queued until one is")
+	}
+
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.batch(ctx)
+		w.drain(ctx)
+		w.watch(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("[Anchoring] Worker stopped")
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// drain keeps claiming batches until nothing is due. Jobs for a network
+// that isn't connected stay queued.
+func (w *Worker) drain(ctx context.Context) {
+	if len(ledger.ConnectedNetworks()) == 0 {
+		return
+	}
+	for ctx.Err() == nil {
+		jobs, err := w.claim(ctx)
+		if err != nil {
+			log.Printf("[Anchoring] Failed to claim jobs: %v", err)
+			return
+		}
+		for i := range jobs {
+			w.process(ctx, &jobs[i])
+		}
+		if len(jobs) < w.opts.BatchSize {
+			return
+		}
+	}
+}
+
+// claim locks a batch of due jobs and marks them processing. Jobs left in
+// processing by a crashed worker are picked up again after LockTimeout; the
+// contract rejects a second anchor of the same document, so a retry can't
+// double-anchor, and process recognises the revert of a resent anchor that
+// already landed.
+func (w *Worker) claim(ctx context.Context) ([]models.AnchorJob, error) {
+	var jobs []models.AnchorJob
+	now := time.Now()
+
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("network IN ?", reachableNetworks())
+		if w.opts.Batching {
+			// Single signatures wait for batch; roots, redundant copies and signer changes are submitted
+			q = q.Where("kind <> ? OR redundant", JobKindSignature)
+		}
+		if err := q.Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_at < ?)",
+			JobQueued, now, JobProcessing, now.Add(-w.opts.LockTimeout)).
+			Order("next_attempt_at").
+			Limit(w.opts.BatchSize).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.ID
+		}
+		return tx.Model(&models.AnchorJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": JobProcessing, "locked_at": now}).Error
+	})
+
+	return jobs, err
+}
+
+// process submits one job and records the outcome. The job moves to
+// submitted and watch decides when it is anchored.
+func (w *Worker) process(ctx context.Context, job *models.AnchorJob) {
+	txHash, err := w.submit(ctx, job)
+	attempts := job.Attempts + 1
+
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down; hand the job back without charging an attempt
+			w.db.Model(job).Updates(map[string]interface{}{"status": JobQueued, "locked_at": nil})
+			return
+		}
+		if errors.Is(err, errChangePending) {
+			// Wait for the earlier change without charging an attempt
+			w.db.Model(job).Updates(map[string]interface{}{"status": JobQueued, "locked_at": nil, "next_attempt_at": time.Now().Add(w.opts.PollInterval)})
+			return
+		}
+		if errors.Is(err, ledger.ErrSignerUnchanged) || (job.Kind == JobKindRevoke && errors.Is(err, ledger.ErrNotAnchored)) {
+			// Already in the requested state, or nothing on-chain to revoke; there is no tx to watch
+			w.db.Model(job).Updates(map[string]interface{}{"status": JobDone, "attempts": attempts, "locked_at": nil, "last_error": nil})
+			w.recordOutcome(job, true, "", "")
+			log.Printf("[Anchoring] %s of %s on %s has nothing to change", job.Kind, job.DocHash, networkOf(job))
+			return
+		}
+		if errors.Is(err, ledger.ErrWouldRevert) {
+			// A resend of an anchor that landed reverts as a duplicate
+			landed, lookupErr := w.adoptLanded(ctx, job, attempts)
+			if lookupErr != nil {
+				w.retry(job, attempts, fmt.Sprintf("%v; checking for an earlier anchor failed: %v", err, lookupErr))
+				return
+			}
+			if landed {
+				return
+			}
+			// The contract refuses it for another reason; retrying won't help
+			log.Printf("[Anchoring] %s would revert, not retrying: %v", job.DocHash, err)
+			w.fail(job, attempts, err.Error(), nil)
+			return
+		}
+		w.retry(job, attempts, err.Error())
+		return
+	}
+
+	now := time.Now()
+	err = w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).Updates(map[string]interface{}{
+			"status":          JobSubmitted,
+			"attempts":        attempts,
+			"locked_at":       nil,
+			"tx_hash":         txHash,
+			"prior_tx_hashes": "",
+			"submitted_at":    now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := revocationOf(tx, job).Update("revocation_tx_hash", txHash).Error; err != nil {
+			return err
+		}
+		return signaturesOf(tx, job).
+			Updates(map[string]interface{}{
+				"ledger_tx_hash": txHash,
+				"ledger_backend": ledger.Network(job.Network).Backend(),
+				"ledger_network": networkOf(job),
+				"status":         SignatureSubmitted,
+			}).Error
+	})
+	if err != nil {
+		// The tx is out; leave the job to be reclaimed rather than losing the hash
+		log.Printf("[Anchoring] Job %s submitted as %s but not recorded: %v", job.ID, txHash, err)
+		return
+	}
+	log.Printf("[Anchoring] Submitted %s to %s in %s (attempt %d)", job.DocHash, networkOf(job), txHash, attempts)
+}
+
+// retry schedules the job again with backoff, or fails it once attempts run out
+func (w *Worker) retry(job *models.AnchorJob, attempts int, lastErr string) {
+	if attempts >= w.opts.MaxAttempts {
+		log.Printf("[Anchoring] Giving up on %s after %d attempts: %s", job.DocHash, attempts, lastErr)
+		w.fail(job, attempts, lastErr, nil)
+		return
+	}
+
+	delay := w.backoff(attempts)
+	log.Printf("[Anchoring] Attempt %d for %s failed, retrying in %s: %s", attempts, job.DocHash, delay, lastErr)
+	w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).Updates(map[string]interface{}{
+			"status":          JobQueued,
+			"attempts":        attempts,
+			"next_attempt_at": time.Now().Add(delay),
+			"locked_at":       nil,
+			"tx_hash":         nil,
+			"last_error":      lastErr,
+		}).Error; err != nil {
+			return err
+		}
+		return signaturesOf(tx, job).
+			Update("status", SignaturePending).Error
+	})
+}
+
+// fail marks the job and its signature failed. sigUpdates can carry extra
+// signature columns, e.g. the block a reverted tx landed in. A failed batch
+// hands its members back to the queue instead; see releaseBatch.
+func (w *Worker) fail(job *models.AnchorJob, attempts int, lastErr string, sigUpdates map[string]interface{}) {
+	if sigUpdates == nil {
+		sigUpdates = map[string]interface{}{}
+	}
+	sigUpdates["status"] = SignatureFailed
+
+	w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).Updates(map[string]interface{}{
+			"status":     JobFailed,
+			"attempts":   attempts,
+			"locked_at":  nil,
+			"last_error": lastErr,
+		}).Error; err != nil {
+			return err
+		}
+		if job.Kind == JobKindBatch {
+			return w.releaseBatch(tx, job, lastErr, sigUpdates)
+		}
+		// The row must not point at a revocation that never took
+		if err := revocationOf(tx, job).Update("revocation_tx_hash", nil).Error; err != nil {
+			return err
+		}
+		return signaturesOf(tx, job).
+			Updates(sigUpdates).Error
+	})
+
+	txHash := ""
+	if job.TxHash != nil {
+		txHash = *job.TxHash
+	}
+	w.recordOutcome(job, false, txHash, lastErr)
+}
+
+// adoptLanded marks job done when the chain already holds its anchor: the
+// send went through but its response was lost, or the job was reclaimed
+// after the write recording it failed, so the resend reverted. It reports
+// whether it did.
+func (w *Worker) adoptLanded(ctx context.Context, job *models.AnchorJob, attempts int) (bool, error) {
+	if job.Kind != JobKindSignature && job.Kind != JobKindBatch {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, submitTimeout)
+	defer cancel()
+
+	l := ledger.Network(job.Network)
+	var record *ledger.SignerRecord
+	var err error
+	if job.Kind == JobKindBatch {
+		record, err = l.BatchRootRecord(ctx, job.DocHash)
+	} else {
+		record, err = l.SignerRecordOf(ctx, job.DocHash, job.SignerDID)
+	}
+	if err != nil {
+		return false, err
+	}
+	// A record of different bytes is an earlier anchor, not this one
+	if record == nil || (job.Kind == JobKindSignature && len(record.Signature) > 0 && !bytes.Equal(record.Signature, job.Payload)) {
+		return false, nil
+	}
+
+	err = w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).Updates(map[string]interface{}{
+			"status":     JobDone,
+			"attempts":   attempts,
+			"locked_at":  nil,
+			"last_error": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return signaturesOf(tx, job).
+			Updates(map[string]interface{}{
+				"ledger_backend": l.Backend(),
+				"ledger_network": networkOf(job),
+				"status":         SignatureAnchored,
+			}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	log.Printf("[Anchoring] %s was already anchored on %s; marking it done", job.DocHash, networkOf(job))
+	return true, nil
+}
+
+// submit sends the job to its network's ledger
+func (w *Worker) submit(ctx context.Context, job *models.AnchorJob) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, submitTimeout)
+	defer cancel()
+	l := ledger.Network(job.Network)
+	switch job.Kind {
+	case JobKindBatch:
+		return l.AnchorBatchRoot(ctx, job.DocHash, job.Payload)
+	case JobKindAddSigner, JobKindRemoveSigner:
+		pending, err := w.changePending(job)
+		if err != nil {
+			return "", err
+		}
+		if pending {
+			return "", errChangePending
+		}
+		return l.SetVerifiedSigner(ctx, job.DocHash, job.Kind == JobKindAddSigner)
+	case JobKindRevoke:
+		return l.RevokeSignature(ctx, job.DocHash, job.SignerDID, w.ownsLegacyRecord(job))
+	}
+	return l.AnchorSignature(ctx, job.DocHash, job.SignerDID, job.Payload, job.HardwareID)
+}
+
+// IsMockTxHash reports whether hash is one of the fabricated "0x"+uuid hashes
+// rows were given before the simulated ledger replaced mock mode. They are 34
+// characters; real and simulated hashes are 66.
+func IsMockTxHash(hash string) bool {
+	b, err := hexutil.Decode(hash)
+	return err != nil || len(b) != common.HashLength
+}
+
+// backoff returns BaseBackoff * 2^(attempts-1), capped at MaxBackoff
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.opts.BaseBackoff
+	for i := 1; i < attempts && delay < w.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.opts.MaxBackoff {
+		delay = w.opts.MaxBackoff
+	}
+	return delay
+}
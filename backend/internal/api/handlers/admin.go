@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/auth"
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+)
+
+// AdminHandler manages users' accounts and their verified signer status on
+// the registry. Every route requires an administrator.
+type AdminHandler struct{}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler() *AdminHandler {
+	return &AdminHandler{}
+}
+
+// SignerNetworkStatus is a signer account's live status on one network
+type SignerNetworkStatus struct {
+	Network  string `json:"network"`
+	Verified *bool  `json:"verified,omitempty"` // Nil while the network can't be read
+	Error    string `json:"error,omitempty"`
+}
+
+// SignerChange is one queued addVerifiedSigner or removeVerifiedSigner call
+type SignerChange struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"` // add_signer or remove_signer
+	Network   string `json:"network"`
+	Status    string `json:"status"` // queued, processing, submitted, done or failed
+	TxHash    string `json:"txHash,omitempty"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError,omitempty"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// SignerStatusResponse is a user's verified signer status
+type SignerStatusResponse struct {
+	UserID       string                `json:"userId"`
+	DID          string                `json:"did"`
+	Status       string                `json:"status"`
+	ChainAddress string                `json:"chainAddress,omitempty"`
+	Networks     []SignerNetworkStatus `json:"networks"`
+	Changes      []SignerChange        `json:"changes"`
+}
+
+// SuspendRequest gives the reason for suspending a user
+type SuspendRequest struct {
+	Reason string `json:"reason"`
+}
+
+// signerReadTimeout bounds reading a signer's status from all networks
+const signerReadTimeout = 10 * time.Second
+
+// SignerStatus handles GET /api/v1/admin/users/:id/signer
+// Reports whether the user's signer account is verified on each network,
+// and every change queued for it with its transaction.
+func (h *AdminHandler) SignerStatus(c echo.Context) error {
+	user, err := findUser(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "User not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, signerStatus(c.Request().Context(), user))
+}
+
+// RegisterSigner handles POST /api/v1/admin/users/:id/signer
+// Queues addVerifiedSigner for the user's signer account on every network.
+func (h *AdminHandler) RegisterSigner(c echo.Context) error {
+	return h.setSigner(c, true)
+}
+
+// UnregisterSigner handles DELETE /api/v1/admin/users/:id/signer
+// Queues removeVerifiedSigner for the user's signer account on every network.
+func (h *AdminHandler) UnregisterSigner(c echo.Context) error {
+	return h.setSigner(c, false)
+}
+
+func (h *AdminHandler) setSigner(c echo.Context, verified bool) error {
+	user, err := findUser(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "User not found",
+		})
+	}
+	if verified && !auth.IsActive(user) {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Only active users can be verified signers",
+		})
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := queueSignerChange(c, tx, user, verified, anchoring.SignerReasonAdmin)
+		return err
+	})
+	if errors.Is(err, anchoring.ErrNoSignerAccount) {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "User has no signer account; configure signer accounts first",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to queue signer change",
+		})
+	}
+	anchoring.Notify()
+
+	return c.JSON(http.StatusAccepted, signerStatus(c.Request().Context(), user))
+}
+
+// SuspendUser handles POST /api/v1/admin/users/:id/suspend
+// Signs the user out everywhere and removes their verified signer.
+func (h *AdminHandler) SuspendUser(c echo.Context) error {
+	var req SuspendRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := findUser(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "User not found",
+		})
+	}
+	if !auth.IsActive(user) {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "User is already " + user.Status,
+		})
+	}
+	if user.ID == auth.CurrentUser(c).ID {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Administrators cannot suspend themselves",
+		})
+	}
+
+	if err := deactivateUser(c, user, auth.UserSuspended, req.Reason); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to suspend user",
+		})
+	}
+
+	return c.JSON(http.StatusOK, signerStatus(c.Request().Context(), user))
+}
+
+// ReinstateUser handles POST /api/v1/admin/users/:id/reinstate
+// Lifts a suspension. Users with a verified identity become verified
+// signers again; they sign in afresh, as their devices were signed out.
+func (h *AdminHandler) ReinstateUser(c echo.Context) error {
+	user, err := findUser(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "User not found",
+		})
+	}
+	if user.Status != auth.UserSuspended {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Only suspended users can be reinstated",
+		})
+	}
+
+	ipAddr := c.RealIP()
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setUserStatus(tx, user, auth.UserActive, ""); err != nil {
+			return err
+		}
+
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"by": auth.CurrentUser(c).DIDAddress,
+		})
+		metadataStr := string(metadata)
+		if err := tx.Create(&models.AuditLog{
+			UserID:     user.ID,
+			ActionType: "account_reinstated",
+			IPAddress:  &ipAddr,
+			Metadata:   &metadataStr,
+			Timestamp:  time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		if user.VNINHash == nil {
+			return nil
+		}
+		_, err := queueSignerChange(c, tx, user, true, anchoring.SignerReasonReinstated)
+		if errors.Is(err, anchoring.ErrNoSignerAccount) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to reinstate user",
+		})
+	}
+	anchoring.Notify()
+
+	return c.JSON(http.StatusOK, signerStatus(c.Request().Context(), user))
+}
+
+// DeleteUser handles DELETE /api/v1/admin/users/:id
+// Closes the account: see deactivateUser.
+func (h *AdminHandler) DeleteUser(c echo.Context) error {
+	user, err := findUser(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "User not found",
+		})
+	}
+	if user.Status == auth.UserDeleted {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "User is already deleted",
+		})
+	}
+	if user.ID == auth.CurrentUser(c).ID {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Administrators cannot delete themselves here; use DELETE /api/v1/profile",
+		})
+	}
+
+	if err := deactivateUser(c, user, auth.UserDeleted, ""); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to delete user",
+		})
+	}
+
+	return c.JSON(http.StatusOK, signerStatus(c.Request().Context(), user))
+}
+
+// findUser looks a user up by ID or DID
+func findUser(idOrDID string) (*models.User, error) {
+	var user models.User
+	query := db.DB.Where("d_id_address = ?", idOrDID)
+	if id, err := uuid.Parse(idOrDID); err == nil {
+		query = db.DB.Where("id = ?", id)
+	}
+	if err := query.First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// signerStatus reads user's signer account on every connected network and
+// lists the changes queued for it
+func signerStatus(ctx context.Context, user *models.User) SignerStatusResponse {
+	response := SignerStatusResponse{
+		UserID:       user.ID.String(),
+		DID:          user.DIDAddress,
+		Status:       user.Status,
+		ChainAddress: user.ChainAddress,
+		Networks:     []SignerNetworkStatus{},
+		Changes:      []SignerChange{},
+	}
+	if user.ChainAddress == "" {
+		return response
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, signerReadTimeout)
+	defer cancel()
+	for _, n := range ledger.Networks() {
+		status := SignerNetworkStatus{Network: n.Name}
+		if l := ledger.Network(n.Name); l == nil {
+			status.Error = "not connected"
+		} else if verified, err := l.IsVerifiedSigner(ctx, user.ChainAddress); err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Verified = &verified
+		}
+		response.Networks = append(response.Networks, status)
+	}
+
+	jobs, err := anchoring.SignerJobs(db.DB, user.ChainAddress)
+	if err != nil {
+		log.Printf("[Admin] Failed to load signer changes for %s: %v", user.DIDAddress, err)
+	}
+	for _, job := range jobs {
+		change := SignerChange{
+			ID:        job.ID.String(),
+			Kind:      job.Kind,
+			Network:   job.Network,
+			Status:    job.Status,
+			Attempts:  job.Attempts,
+			CreatedAt: job.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: job.UpdatedAt.Format(time.RFC3339),
+		}
+		if job.TxHash != nil {
+			change.TxHash = *job.TxHash
+		}
+		if job.LastError != nil {
+			change.LastError = *job.LastError
+		}
+		response.Changes = append(response.Changes, change)
+	}
+	return response
+}
+
+// queueSignerChange queues adding or removing user's verified signer and
+// audits it; nothing is audited when no network needs the change. Call it
+// inside a transaction and Notify the worker once it has committed.
+func queueSignerChange(c echo.Context, tx *gorm.DB, user *models.User, verified bool, reason string) ([]models.AnchorJob, error) {
+	jobs, err := anchoring.SetSigner(tx, user, verified)
+	if err != nil || len(jobs) == 0 {
+		return jobs, err
+	}
+
+	action := "signer_unregister"
+	if verified {
+		action = "signer_register"
+	}
+	by := user.DIDAddress
+	if actor := auth.CurrentUser(c); actor != nil {
+		by = actor.DIDAddress
+	}
+	ids := make([]uuid.UUID, len(jobs))
+	networks := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+		networks[i] = job.Network
+	}
+
+	ipAddr := c.RealIP()
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"address":  user.ChainAddress,
+		"reason":   reason,
+		"by":       by,
+		"jobIds":   ids,
+		"networks": networks,
+	})
+	metadataStr := string(metadata)
+	return jobs, tx.Create(&models.AuditLog{
+		UserID:     user.ID,
+		ActionType: action,
+		IPAddress:  &ipAddr,
+		Metadata:   &metadataStr,
+		Timestamp:  time.Now(),
+	}).Error
+}
+
+// deactivateUser suspends or deletes user: their devices and tokens are
+// revoked and their verified signer removed. Deleting also clears the
+// personal data; the DID and signer account stay, as the user's signatures
+// and anchors refer to them. A user closing their own account has the
+// identity cleared too, so they may verify afresh; when an administrator
+// deletes it, the vNIN hash stays on the deleted row as a tombstone, and
+// verifying that vNIN again is refused.
+func deactivateUser(c echo.Context, user *models.User, status, reason string) error {
+	var deviceIDs []uuid.UUID
+	ipAddr := c.RealIP()
+	actor := auth.CurrentUser(c)
+	byAdmin := actor.ID != user.ID
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setUserStatus(tx, user, status, reason); err != nil {
+			return err
+		}
+		if status == auth.UserDeleted {
+			updates := map[string]interface{}{
+				"device_pub_key":    "",
+				"classical_pub_key": "",
+				"full_name":         "",
+				"email":             "",
+			}
+			if !byAdmin {
+				updates["vnin_hash"] = nil
+			}
+			if err := tx.Model(user).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.TrustedDevice{}).
+			Where("user_id = ? AND is_active = ?", user.ID, true).
+			Pluck("id", &deviceIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TrustedDevice{}).
+			Where("user_id = ?", user.ID).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		action, signerReason := "account_suspended", anchoring.SignerReasonSuspended
+		if status == auth.UserDeleted {
+			action, signerReason = "account_deleted", anchoring.SignerReasonDeleted
+		}
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"reason":         reason,
+			"by":             actor.DIDAddress,
+			"devicesRevoked": len(deviceIDs),
+			"identityKept":   status == auth.UserDeleted && byAdmin,
+		})
+		metadataStr := string(metadata)
+		if err := tx.Create(&models.AuditLog{
+			UserID:     user.ID,
+			ActionType: action,
+			IPAddress:  &ipAddr,
+			Metadata:   &metadataStr,
+			Timestamp:  time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		_, err := queueSignerChange(c, tx, user, false, signerReason)
+		if errors.Is(err, anchoring.ErrNoSignerAccount) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	anchoring.Notify()
+
+	if err := auth.RevokeUserTokens(user.ID); err != nil {
+		log.Printf("[Admin] Failed to revoke tokens of %s: %v", user.DIDAddress, err)
+	}
+	return nil
+}
+
+// setUserStatus moves user to status, recording why and when
+func setUserStatus(tx *gorm.DB, user *models.User, status, reason string) error {
+	now := time.Now()
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"status":            status,
+		"status_reason":     reason,
+		"status_changed_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	user.Status, user.StatusReason, user.StatusChangedAt = status, reason, &now
+	return nil
+}
@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/inkless/backend/internal/auth"
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/signing"
+)
+
+// AuthHandler issues and refreshes API credentials
+type AuthHandler struct {
+	jwtSecret string
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(jwtSecret string) *AuthHandler {
+	return &AuthHandler{jwtSecret: jwtSecret}
+}
+
+// TokenRequest exchanges a verification grant for tokens on a new device
+type TokenRequest struct {
+	VerificationToken string `json:"verificationToken" validate:"required"`
+	DeviceName        string `json:"deviceName"`
+	DeviceType        string `json:"deviceType"` // mobile, desktop, tablet
+}
+
+// RefreshRequest rotates a refresh token
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// ChallengeRequest asks for a login nonce for a DID
+type ChallengeRequest struct {
+	DID string `json:"did" validate:"required"`
+}
+
+// ChallengeResponse carries the nonce a device must sign
+type ChallengeResponse struct {
+	ChallengeID string `json:"challengeId"`
+	Nonce       string `json:"nonce"`
+	Message     string `json:"message"` // Exact UTF-8 string to sign
+	ExpiresAt   string `json:"expiresAt"`
+}
+
+// ChallengeAnswerRequest proves possession of the registered device key
+type ChallengeAnswerRequest struct {
+	ChallengeID string `json:"challengeId" validate:"required"`
+	Algorithm   string `json:"algorithm" validate:"required"` // ecdsa-p256, ml-dsa-44, ml-dsa-65, ml-dsa-87
+	Signature   []byte `json:"signature" validate:"required"`
+	DeviceID    string `json:"deviceId"` // Existing trusted device to resume, if any
+	DeviceName  string `json:"deviceName"`
+	DeviceType  string `json:"deviceType"`
+}
+
+// TokenResponse carries a fresh access/refresh token pair
+type TokenResponse struct {
+	AccessToken      string `json:"accessToken"`
+	TokenType        string `json:"tokenType"`
+	ExpiresIn        int    `json:"expiresIn"` // Access token lifetime in seconds
+	RefreshToken     string `json:"refreshToken"`
+	RefreshExpiresAt string `json:"refreshExpiresAt"`
+	DeviceID         string `json:"deviceId"`
+	DID              string `json:"did"`
+}
+
+// Token handles POST /api/v1/auth/token
+// Exchanges the grant returned by identity verification for an access token
+// and a refresh token bound to the requesting device.
+func (h *AuthHandler) Token(c echo.Context) error {
+	var req TokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.VerificationToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "verificationToken is required",
+		})
+	}
+
+	claims, err := auth.ParseVerificationGrant(h.jwtSecret, req.VerificationToken)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid or expired verification token",
+		})
+	}
+
+	var user models.User
+	if err := db.DB.Where("d_id_address = ?", claims.Subject).First(&user).Error; err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unknown user",
+		})
+	}
+	if !auth.IsActive(&user) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": auth.InactiveMessage(&user),
+		})
+	}
+
+	// The grant is burned in the transaction that registers the device and
+	// starts its session, so it yields exactly one of each
+	var deviceID uuid.UUID
+	var refreshToken string
+	var refreshExpiresAt time.Time
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := auth.ConsumeVerificationGrant(tx, claims); err != nil {
+			return err
+		}
+
+		// Grants from device linking already name the approved device
+		if claims.DeviceID != "" {
+			var device models.TrustedDevice
+			if err := tx.Where("id = ? AND user_id = ? AND is_active = ?", claims.DeviceID, user.ID, true).First(&device).Error; err != nil {
+				return errDeviceUntrusted
+			}
+			deviceID = device.ID
+		} else {
+			device, err := createTrustedDevice(c, tx, user.ID, req.DeviceName, req.DeviceType)
+			if err != nil {
+				return err
+			}
+			deviceID = device.ID
+		}
+
+		var err error
+		refreshToken, refreshExpiresAt, err = auth.IssueRefreshToken(tx, user.ID, deviceID, uuid.Nil)
+		return err
+	})
+	switch {
+	case errors.Is(err, auth.ErrGrantUsed):
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid or expired verification token",
+		})
+	case errors.Is(err, errDeviceUntrusted):
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Linked device is no longer trusted",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to register device",
+		})
+	}
+
+	accessToken, _, err := auth.IssueAccessToken(h.jwtSecret, user.DIDAddress, deviceID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to issue access token",
+		})
+	}
+
+	return c.JSON(http.StatusOK, newTokenResponse(accessToken, refreshToken, refreshExpiresAt, deviceID, user.DIDAddress))
+}
+
+// errDeviceUntrusted refuses a grant naming a device that was revoked since
+var errDeviceUntrusted = errors.New("linked device is no longer trusted")
+
+// Challenge handles POST /api/v1/auth/challenge
+// Issues a one-time nonce for passwordless login with the device key.
+func (h *AuthHandler) Challenge(c echo.Context) error {
+	var req ChallengeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.DID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "did is required",
+		})
+	}
+
+	challenge, err := auth.NewChallenge(req.DID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create challenge",
+		})
+	}
+
+	return c.JSON(http.StatusOK, newChallengeResponse(challenge))
+}
+
+func newChallengeResponse(challenge *models.AuthChallenge) *ChallengeResponse {
+	return &ChallengeResponse{
+		ChallengeID: challenge.ID.String(),
+		Nonce:       challenge.Nonce,
+		Message:     string(auth.ChallengeMessage(challenge)),
+		ExpiresAt:   challenge.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// AnswerChallenge handles POST /api/v1/auth/challenge/response
+// Verifies the nonce signature against the user's registered DevicePubKey
+// and starts a session on success.
+func (h *AuthHandler) AnswerChallenge(c echo.Context) error {
+	var req ChallengeAnswerRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	challengeID, err := uuid.Parse(req.ChallengeID)
+	if err != nil || len(req.Signature) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "challengeId and signature are required",
+		})
+	}
+
+	alg, err := signing.ParseAlgorithm(req.Algorithm)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	challenge, err := auth.ConsumeChallenge(challengeID)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid or expired challenge",
+		})
+	}
+
+	var user models.User
+	if err := db.DB.Where("d_id_address = ?", challenge.DIDAddress).First(&user).Error; err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Challenge verification failed",
+		})
+	}
+	if !auth.IsActive(&user) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": auth.InactiveMessage(&user),
+		})
+	}
+
+	ipAddr := c.RealIP()
+	if _, err := verifyDeviceKeySignature(&user, alg, auth.ChallengeMessage(challenge), req.Signature); err != nil {
+		db.DB.Create(&models.AuditLog{
+			UserID:     user.ID,
+			ActionType: "challenge_login_failed",
+			IPAddress:  &ipAddr,
+			Timestamp:  time.Now(),
+		})
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Challenge verification failed",
+		})
+	}
+
+	// Resume a known device if the client names one, otherwise register this one
+	var device models.TrustedDevice
+	if deviceID, err := uuid.Parse(req.DeviceID); err == nil &&
+		db.DB.Where("id = ? AND user_id = ? AND is_active = ?", deviceID, user.ID, true).First(&device).Error == nil {
+		db.DB.Model(&device).Update("last_seen_at", time.Now())
+	} else {
+		created, err := createTrustedDevice(c, db.DB, user.ID, req.DeviceName, req.DeviceType)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to register device",
+			})
+		}
+		device = *created
+	}
+
+	metadata := fmt.Sprintf(`{"algorithm": "%s", "deviceId": "%s"}`, alg, device.ID)
+	db.DB.Create(&models.AuditLog{
+		UserID:     user.ID,
+		ActionType: "challenge_login",
+		IPAddress:  &ipAddr,
+		Metadata:   &metadata,
+		Timestamp:  time.Now(),
+	})
+
+	return h.startSession(c, &user, device.ID)
+}
+
+// Refresh handles POST /api/v1/auth/refresh
+// Refresh tokens are single use. Presenting a spent token revokes every
+// token in its family, signing out whoever holds the stolen copy.
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "refreshToken is required",
+		})
+	}
+
+	spent, refreshToken, refreshExpiresAt, err := auth.RotateRefreshToken(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrRefreshTokenReused) {
+			ipAddr := c.RealIP()
+			db.DB.Create(&models.AuditLog{
+				UserID:     spent.UserID,
+				ActionType: "refresh_token_reuse",
+				IPAddress:  &ipAddr,
+				Timestamp:  time.Now(),
+			})
+		}
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid or expired refresh token",
+		})
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, spent.UserID).Error; err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unknown user",
+		})
+	}
+	if !auth.IsActive(&user) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": auth.InactiveMessage(&user),
+		})
+	}
+
+	accessToken, _, err := auth.IssueAccessToken(h.jwtSecret, user.DIDAddress, spent.DeviceID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to issue access token",
+		})
+	}
+
+	db.DB.Model(&models.TrustedDevice{}).Where("id = ?", spent.DeviceID).Update("last_seen_at", time.Now())
+
+	return c.JSON(http.StatusOK, newTokenResponse(accessToken, refreshToken, refreshExpiresAt, spent.DeviceID, user.DIDAddress))
+}
+
+// verifyDeviceKeySignature checks a signature against the user's primary
+// device key, optional classical key and the keys of any active devices added
+// through linking. It returns the KeyID of the key that verified it.
+func verifyDeviceKeySignature(user *models.User, alg signing.Algorithm, message, signature []byte) (string, error) {
+	keys := []string{user.DevicePubKey, user.ClassicalPubKey}
+	var linked []string
+	db.DB.Model(&models.TrustedDevice{}).
+		Where("user_id = ? AND is_active = ? AND public_key <> ''", user.ID, true).
+		Pluck("public_key", &linked)
+	keys = append(keys, linked...)
+
+	for _, key := range keys {
+		pubKey, err := signing.DecodePublicKey(key)
+		if err != nil {
+			continue
+		}
+		err = signing.Verify(alg, pubKey, message, signature)
+		if err == nil {
+			return signing.KeyID(pubKey), nil
+		}
+		// Policy failures don't depend on the key, so stop early
+		if errors.Is(err, signing.ErrAlgorithmForbidden) || errors.Is(err, signing.ErrUnsupportedAlgorithm) {
+			return "", err
+		}
+	}
+	return "", signing.ErrInvalidSignature
+}
+
+// startSession issues an access token and starts a new refresh token family
+func (h *AuthHandler) startSession(c echo.Context, user *models.User, deviceID uuid.UUID) error {
+	accessToken, _, err := auth.IssueAccessToken(h.jwtSecret, user.DIDAddress, deviceID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to issue access token",
+		})
+	}
+
+	refreshToken, refreshExpiresAt, err := auth.IssueRefreshToken(db.DB, user.ID, deviceID, uuid.Nil)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to issue refresh token",
+		})
+	}
+
+	return c.JSON(http.StatusOK, newTokenResponse(accessToken, refreshToken, refreshExpiresAt, deviceID, user.DIDAddress))
+}
+
+func newTokenResponse(accessToken, refreshToken string, refreshExpiresAt time.Time, deviceID uuid.UUID, did string) TokenResponse {
+	return TokenResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(auth.AccessTokenTTL.Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt.Format(time.RFC3339),
+		DeviceID:         deviceID.String(),
+		DID:              did,
+	}
+}
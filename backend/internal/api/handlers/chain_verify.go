@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"github.com/inkless/backend/internal/merkle"
+)
+
+// chainLookupTimeout bounds the on-chain read in Verify
+const chainLookupTimeout = 10 * time.Second
+
+// chainClockSkew is how far an on-chain timestamp may precede the row it
+// anchors before the two are treated as disagreeing
+const chainClockSkew = 5 * time.Minute
+
+// Per-source verification results
+const (
+	SourceValid       = "valid"
+	SourceInvalid     = "invalid"     // Postgres: a signature is revoked or uses a forbidden algorithm
+	SourceFailed      = "failed"      // Postgres: a signature's anchor failed
+	SourceMismatch    = "mismatch"    // Chain: disagrees with Postgres
+	SourcePending     = "pending"     // Some signatures are not anchored yet
+	SourceNotFound    = "not_found"   // No record in this source
+	SourceUnavailable = "unavailable" // Chain: no ledger configured, or the read failed
+)
+
+// Fields compared between a Postgres row and its on-chain record
+const (
+	MismatchSigner      = "signer" // Present in one source only
+	MismatchTimestamp   = "timestamp"
+	MismatchHardwareID  = "hardwareId"
+	MismatchRevoked     = "revoked"
+	MismatchMerkleProof = "merkleProof"
+	MismatchAccount     = "account" // Approved on-chain by an account other than the signer's
+)
+
+// VerifySources reports what each source says about the document on its own
+type VerifySources struct {
+	DB    string `json:"db"`    // valid, invalid, failed, pending, not_found
+	Chain string `json:"chain"` // valid, mismatch, pending, not_found, unavailable
+}
+
+// fetchChainSigners reads the document's signers from the contract. It
+// reports false when no ledger is configured or the read fails, in which
+// case Verify answers from Postgres alone.
+func fetchChainSigners(docHash string, signatures []models.SignatureMetadata) ([]ledger.SignerRecord, bool) {
+	if !ledger.IsConnected() {
+		return nil, false
+	}
+
+	dids := make([]string, len(signatures))
+	for i, sig := range signatures {
+		dids[i] = sig.Signer.DIDAddress
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chainLookupTimeout)
+	defer cancel()
+
+	records, err := ledger.Global.VerifySignature(ctx, docHash, dids)
+	if err != nil {
+		log.Printf("[Verify] On-chain lookup for %s failed: %v", docHash, err)
+		return nil, false
+	}
+	return records, true
+}
+
+// crossCheck compares each Postgres signature (signers[i] describes
+// signatures[i]) with its on-chain record, flags every field that disagrees
+// and appends signers that exist only on-chain. A legacy document-keyed
+// record predates co-signing, so it belongs to the first signer; a batched
+// signature is backed by its batch root's record.
+func crossCheck(signers []SignerInfo, signatures []models.SignatureMetadata, records []ledger.SignerRecord) []SignerInfo {
+	byDID := make(map[string]ledger.SignerRecord, len(records))
+	var legacy *ledger.SignerRecord
+	var unknown []ledger.SignerRecord
+	for i, r := range records {
+		switch {
+		case r.Legacy:
+			legacy = &records[i]
+		case r.DID != "":
+			byDID[r.DID] = r
+		default:
+			unknown = append(unknown, r)
+		}
+	}
+
+	roots := make(map[string]*ledger.SignerRecord)
+	for i := range signatures {
+		sig := &signatures[i]
+		record, ok := byDID[sig.Signer.DIDAddress]
+		if !ok && i == 0 && legacy != nil {
+			record, ok = *legacy, true
+		}
+		batched := false
+		if !ok && signers[i].MerkleProof != nil {
+			if root := batchRootRecord(roots, signers[i].MerkleProof.Root); root != nil {
+				record, ok, batched = *root, true, true
+			}
+		}
+
+		onChain := ok
+		signers[i].OnChain = &onChain
+		if !ok {
+			if anchoring.ExpectsChainRecord(sig) && anchoredHere(sig) {
+				flagMismatch(&signers[i], MismatchSigner)
+			}
+			continue
+		}
+
+		signers[i].SignerID = ledger.SignerID(sig.Signer.DIDAddress).Hex()
+		signers[i].ChainTimestamp = record.Timestamp.Format(time.RFC3339)
+		signers[i].ChainHardwareID = record.HardwareID
+		signers[i].ChainRevoked = record.Revoked
+		signers[i].ChainAccount = record.Account
+		for _, field := range compareRecord(sig, &record, batched, signers[i].MerkleProof) {
+			flagMismatch(&signers[i], field)
+		}
+	}
+
+	if legacy != nil && len(signatures) == 0 {
+		unknown = append(unknown, *legacy)
+	}
+	for _, r := range unknown {
+		onChain := true
+		signers = append(signers, SignerInfo{
+			Timestamp:       r.Timestamp.Format(time.RFC3339),
+			Status:          anchoring.SignatureAnchored,
+			OnChain:         &onChain,
+			SignerID:        r.SignerID,
+			ChainTimestamp:  r.Timestamp.Format(time.RFC3339),
+			ChainHardwareID: r.HardwareID,
+			ChainRevoked:    r.Revoked,
+			ChainAccount:    r.Account,
+			PQCSignature:    r.Signature,
+		})
+		flagMismatch(&signers[len(signers)-1], MismatchSigner)
+	}
+
+	return signers
+}
+
+// compareRecord lists the fields on which a Postgres row and its on-chain record disagree
+func compareRecord(sig *models.SignatureMetadata, record *ledger.SignerRecord, batched bool, proof *MerkleProofInfo) []string {
+	var fields []string
+
+	// The chain stamps the block time, which the receipt watcher copies to the
+	// row; before that the anchor can only postdate the row
+	chainTime := record.Timestamp.Unix()
+	if sig.BlockTimestamp != nil {
+		if sig.BlockTimestamp.Unix() != chainTime {
+			fields = append(fields, MismatchTimestamp)
+		}
+	} else if chainTime < sig.CreatedAt.Add(-chainClockSkew).Unix() {
+		fields = append(fields, MismatchTimestamp)
+	}
+
+	// A batch root carries no device; its proof ties the row to it instead
+	if batched {
+		if !proof.Valid {
+			fields = append(fields, MismatchMerkleProof)
+		}
+	} else if record.HardwareID != ledger.HardwareIDHash(sig.HardwareID).Hex() {
+		fields = append(fields, MismatchHardwareID)
+	}
+
+	// An anchor approved by a signer account must be approved by this signer's
+	if record.Account != "" && !strings.EqualFold(record.Account, sig.Signer.ChainAddress) {
+		fields = append(fields, MismatchAccount)
+	}
+
+	// Revocations are submitted before the row changes, so the chain may lag
+	// a revoked row; a row un-revoked behind the chain's back is tampering
+	if record.Revoked && sig.Status != anchoring.SignatureRevoked {
+		fields = append(fields, MismatchRevoked)
+	}
+
+	return fields
+}
+
+// anchoredHere reports whether sig was anchored on the ledger Verify reads.
+// A row anchored on another backend or network can't be found on this one,
+// and the simulated chain forgets everything on restart, so its anchors are
+// unverifiable even while it is still the backend, as the indexer treats them.
+func anchoredHere(sig *models.SignatureMetadata) bool {
+	if sig.LedgerNetwork != "" && sig.LedgerNetwork != ledger.PrimaryNetwork() {
+		return false
+	}
+	if sig.LedgerBackend == ledger.BackendSimulated {
+		return false
+	}
+	return sig.LedgerBackend == "" || sig.LedgerBackend == ledger.BackendName()
+}
+
+// flagMismatch records a disagreeing field on a signer
+func flagMismatch(signer *SignerInfo, field string) {
+	signer.Status = SourceMismatch
+	signer.Mismatches = append(signer.Mismatches, field)
+}
+
+// chainSource summarises what the chain says once crossCheck has run
+func chainSource(signers []SignerInfo) string {
+	anchored, pending := 0, 0
+	for _, s := range signers {
+		if len(s.Mismatches) > 0 {
+			return SourceMismatch
+		}
+		if s.OnChain != nil && *s.OnChain {
+			anchored++
+		} else {
+			pending++
+		}
+	}
+	switch {
+	case pending > 0:
+		return SourcePending
+	case anchored == 0:
+		return SourceNotFound
+	default:
+		return SourceValid
+	}
+}
+
+// batchRootRecord reads a batch root's on-chain record once per Verify call
+func batchRootRecord(cache map[string]*ledger.SignerRecord, root string) *ledger.SignerRecord {
+	if record, seen := cache[root]; seen {
+		return record
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chainLookupTimeout)
+	defer cancel()
+
+	record, err := ledger.Global.BatchRootRecord(ctx, root)
+	if err != nil {
+		log.Printf("[Verify] On-chain lookup for batch root %s failed: %v", root, err)
+	}
+	cache[root] = record
+	return record
+}
+
+// merkleProofs returns the inclusion proof of each batched signature, keyed
+// by signature ID. A proof is valid only if the stored leaf matches the one
+// recomputed from the row and the anchored payload, and leads to the root.
+func merkleProofs(signatures []models.SignatureMetadata) map[uuid.UUID]*MerkleProofInfo {
+	var ids []uuid.UUID
+	for _, sig := range signatures {
+		if sig.MerkleRoot != "" {
+			ids = append(ids, sig.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// The leaf commits to the anchored payload, which only the job keeps
+	var jobs []models.AnchorJob
+	db.DB.Where("signature_id IN ? AND kind = ? AND NOT redundant", ids, anchoring.JobKindSignature).Find(&jobs)
+	payloads := make(map[uuid.UUID][]byte, len(jobs))
+	for _, job := range jobs {
+		payloads[*job.SignatureID] = job.Payload
+	}
+
+	proofs := make(map[uuid.UUID]*MerkleProofInfo, len(ids))
+	for _, sig := range signatures {
+		if sig.MerkleRoot == "" || sig.MerkleProof == nil || sig.MerkleIndex == nil {
+			continue
+		}
+		var steps []merkle.ProofStep
+		if err := json.Unmarshal([]byte(*sig.MerkleProof), &steps); err != nil {
+			log.Printf("[Verify] Malformed Merkle proof on %s: %v", sig.ID, err)
+			continue
+		}
+		info := &MerkleProofInfo{
+			Root:      sig.MerkleRoot,
+			Leaf:      sig.MerkleLeaf,
+			LeafIndex: *sig.MerkleIndex,
+			Proof:     steps,
+			SignerID:  ledger.SignerID(sig.Signer.DIDAddress).Hex(),
+		}
+		if payload, ok := payloads[sig.ID]; ok {
+			info.PayloadHash = crypto.Keccak256Hash(payload).Hex()
+			leaf := anchoring.LeafHash(sig.DocHash, sig.Signer.DIDAddress, payload)
+			info.Valid = leaf.Hex() == sig.MerkleLeaf &&
+				merkle.Verify(leaf, steps, common.HexToHash(sig.MerkleRoot))
+		}
+		if sig.LedgerTxHash != nil {
+			info.RootTxHash = *sig.LedgerTxHash
+		}
+		proofs[sig.ID] = info
+	}
+	return proofs
+}
+
+// redundantCopies returns each signature's copies on secondary networks,
+// with the status of each copy's revocation, keyed by signature ID
+func redundantCopies(signatures []models.SignatureMetadata) (map[uuid.UUID][]NetworkCopy, error) {
+	if len(signatures) == 0 {
+		return nil, nil
+	}
+	ids := make([]uuid.UUID, len(signatures))
+	for i, sig := range signatures {
+		ids[i] = sig.ID
+	}
+
+	var jobs []models.AnchorJob
+	if err := db.DB.Where("signature_id IN ? AND kind IN ? AND redundant", ids, []string{anchoring.JobKindSignature, anchoring.JobKindRevoke}).
+		Order("network").
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	revocations := make(map[uuid.UUID]map[string]string)
+	for _, job := range jobs {
+		if job.Kind == anchoring.JobKindRevoke {
+			if revocations[*job.SignatureID] == nil {
+				revocations[*job.SignatureID] = make(map[string]string)
+			}
+			revocations[*job.SignatureID][job.Network] = job.Status
+		}
+	}
+
+	copies := make(map[uuid.UUID][]NetworkCopy, len(jobs))
+	for _, job := range jobs {
+		if job.Kind != anchoring.JobKindSignature {
+			continue
+		}
+		c := NetworkCopy{Network: job.Network, Status: job.Status, Revocation: revocations[*job.SignatureID][job.Network]}
+		if job.TxHash != nil {
+			c.TxHash = *job.TxHash
+		}
+		copies[*job.SignatureID] = append(copies[*job.SignatureID], c)
+	}
+	return copies, nil
+}
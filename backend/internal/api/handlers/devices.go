@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/db"
 	"github.com/inkless/backend/internal/db/models"
 )
@@ -38,16 +41,17 @@ type RegisterDeviceRequest struct {
 
 // ListDevices handles GET /api/v1/devices
 func (h *DeviceHandler) ListDevices(c echo.Context) error {
-	// For MVP, we'll use a mock user ID. In production, this comes from auth middleware.
-	// Get all devices (for demo, we return all devices in the system)
+	user := auth.CurrentUser(c)
+
 	var devices []models.TrustedDevice
-	if err := db.DB.Order("last_seen_at desc").Limit(10).Find(&devices).Error; err != nil {
+	if err := db.DB.Where("user_id = ?", user.ID).Order("last_seen_at desc").Limit(10).Find(&devices).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to fetch devices",
 		})
 	}
 
 	// Get current request info to mark current device
+	currentDevice := auth.CurrentDeviceID(c)
 	currentIP := c.RealIP()
 
 	response := make([]DeviceResponse, len(devices))
@@ -59,7 +63,7 @@ func (h *DeviceHandler) ListDevices(c echo.Context) error {
 			Location:   device.Location,
 			LastSeenAt: device.LastSeenAt.Format(time.RFC3339),
 			IsActive:   device.IsActive,
-			IsCurrent:  device.IPAddress == currentIP,
+			IsCurrent:  device.ID == currentDevice || (currentDevice == uuid.Nil && device.IPAddress == currentIP),
 		}
 	}
 
@@ -75,42 +79,10 @@ func (h *DeviceHandler) RegisterDevice(c echo.Context) error {
 		})
 	}
 
-	if req.DeviceName == "" {
-		req.DeviceName = "Unknown Device"
-	}
-	if req.DeviceType == "" {
-		req.DeviceType = "desktop"
-	}
-
-	// Get request metadata
-	userAgent := c.Request().UserAgent()
-	ipAddress := c.RealIP()
-
-	// For MVP, create a mock user or find existing
-	var user models.User
-	db.DB.FirstOrCreate(&user, models.User{
-		DIDAddress:   "did:inkless:demo",
-		DevicePubKey: "demo_pub_key",
-	})
-
-	// Derive location from IP (simplified - in production use a geo-IP service)
-	location := "Unknown"
-	if ipAddress == "127.0.0.1" || ipAddress == "::1" {
-		location = "Local"
-	}
-
-	device := models.TrustedDevice{
-		UserID:     user.ID,
-		DeviceName: req.DeviceName,
-		DeviceType: req.DeviceType,
-		UserAgent:  userAgent,
-		IPAddress:  ipAddress,
-		Location:   location,
-		LastSeenAt: time.Now(),
-		IsActive:   true,
-	}
+	user := auth.CurrentUser(c)
 
-	if err := db.DB.Create(&device).Error; err != nil {
+	device, err := createTrustedDevice(c, db.DB, user.ID, req.DeviceName, req.DeviceType)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to register device",
 		})
@@ -138,8 +110,10 @@ func (h *DeviceHandler) RemoveDevice(c echo.Context) error {
 		})
 	}
 
-	// Soft delete - just mark as inactive
-	result := db.DB.Model(&models.TrustedDevice{}).Where("id = ?", parsedID).Update("is_active", false)
+	user := auth.CurrentUser(c)
+
+	// Soft delete - just mark as inactive (scoped to the caller's own devices)
+	result := db.DB.Model(&models.TrustedDevice{}).Where("id = ? AND user_id = ?", parsedID, user.ID).Update("is_active", false)
 	if result.Error != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to remove device",
@@ -152,6 +126,12 @@ func (h *DeviceHandler) RemoveDevice(c echo.Context) error {
 		})
 	}
 
+	if err := auth.RevokeDeviceTokens(parsedID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to revoke device tokens",
+		})
+	}
+
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "Device removed successfully",
 	})
@@ -159,28 +139,234 @@ func (h *DeviceHandler) RemoveDevice(c echo.Context) error {
 
 // RevokeAllDevices handles POST /api/v1/devices/revoke-all
 func (h *DeviceHandler) RevokeAllDevices(c echo.Context) error {
-	// For MVP, get the demo user
-	var user models.User
-	if err := db.DB.Where("did_address = ?", "did:inkless:demo").First(&user).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{
-			"error": "User not found",
-		})
-	}
+	user := auth.CurrentUser(c)
 
-	// Mark all devices as inactive except current
-	currentIP := c.RealIP()
-	result := db.DB.Model(&models.TrustedDevice{}).
-		Where("user_id = ? AND ip_address != ?", user.ID, currentIP).
-		Update("is_active", false)
+	// Mark all devices as inactive except current. Token-bound requests know
+	// their device; fall back to the request IP for legacy callers.
+	query := db.DB.Model(&models.TrustedDevice{}).Where("user_id = ? AND is_active = ?", user.ID, true)
+	if currentDevice := auth.CurrentDeviceID(c); currentDevice != uuid.Nil {
+		query = query.Where("id != ?", currentDevice)
+	} else {
+		query = query.Where("ip_address != ?", c.RealIP())
+	}
 
-	if result.Error != nil {
+	var deviceIDs []uuid.UUID
+	if err := query.Pluck("id", &deviceIDs).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to revoke devices",
 		})
 	}
 
+	if len(deviceIDs) > 0 {
+		if err := db.DB.Model(&models.TrustedDevice{}).Where("id IN ?", deviceIDs).Update("is_active", false).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to revoke devices",
+			})
+		}
+		if err := auth.RevokeDeviceTokens(deviceIDs...); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to revoke device tokens",
+			})
+		}
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message":        "All other devices have been signed out",
-		"devicesRevoked": result.RowsAffected,
+		"devicesRevoked": len(deviceIDs),
+	})
+}
+
+// createTrustedDevice records the device making the current request
+func createTrustedDevice(c echo.Context, tx *gorm.DB, userID uuid.UUID, name, deviceType string) (*models.TrustedDevice, error) {
+	if name == "" {
+		name = "Unknown Device"
+	}
+	if deviceType == "" {
+		deviceType = "desktop"
+	}
+
+	// Get request metadata
+	ipAddress := c.RealIP()
+
+	// Derive location from IP (simplified - in production use a geo-IP service)
+	location := "Unknown"
+	if ipAddress == "127.0.0.1" || ipAddress == "::1" {
+		location = "Local"
+	}
+
+	device := models.TrustedDevice{
+		UserID:     userID,
+		DeviceName: name,
+		DeviceType: deviceType,
+		UserAgent:  c.Request().UserAgent(),
+		IPAddress:  ipAddress,
+		Location:   location,
+		LastSeenAt: time.Now(),
+		IsActive:   true,
+	}
+
+	if err := tx.Create(&device).Error; err != nil {
+		return nil, err
+	}
+
+	return &device, nil
+}
+
+// LinkRequestResponse represents a pending device link in API responses
+type LinkRequestResponse struct {
+	ID         string `json:"id"`
+	DeviceName string `json:"deviceName"`
+	IPAddress  string `json:"ipAddress"`
+	Status     string `json:"status"`
+	CreatedAt  string `json:"createdAt"`
+	ExpiresAt  string `json:"expiresAt"`
+}
+
+// ListLinkRequests handles GET /api/v1/devices/link-requests
+func (h *DeviceHandler) ListLinkRequests(c echo.Context) error {
+	user := auth.CurrentUser(c)
+
+	var links []models.DeviceLinkRequest
+	if err := db.DB.Where("user_id = ? AND status = ? AND expires_at > ?", user.ID, "pending", time.Now()).
+		Order("created_at desc").Find(&links).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to fetch link requests",
+		})
+	}
+
+	response := make([]LinkRequestResponse, len(links))
+	for i, link := range links {
+		response[i] = LinkRequestResponse{
+			ID:         link.ID.String(),
+			DeviceName: link.DeviceName,
+			IPAddress:  link.IPAddress,
+			Status:     link.Status,
+			CreatedAt:  link.CreatedAt.Format(time.RFC3339),
+			ExpiresAt:  link.ExpiresAt.Format(time.RFC3339),
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ApproveLinkRequest handles POST /api/v1/devices/link-requests/:id/approve
+// Trusts the new device's key so it can claim credentials for this identity.
+func (h *DeviceHandler) ApproveLinkRequest(c echo.Context) error {
+	var req RegisterDeviceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	link, err := findPendingLinkRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Link request not found or expired",
+		})
+	}
+
+	if req.DeviceName == "" {
+		req.DeviceName = "Linked Device"
+	}
+	if req.DeviceType == "" {
+		req.DeviceType = "mobile"
+	}
+
+	approver := auth.CurrentDeviceID(c)
+	device := models.TrustedDevice{
+		UserID:     link.UserID,
+		DeviceName: req.DeviceName,
+		DeviceType: req.DeviceType,
+		UserAgent:  link.DeviceName,
+		IPAddress:  link.IPAddress,
+		Location:   "Unknown",
+		PublicKey:  link.DevicePubKey,
+		LastSeenAt: time.Now(),
+		IsActive:   true,
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&device).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"status":    "approved",
+			"device_id": device.ID,
+		}
+		if approver != uuid.Nil {
+			updates["approved_by"] = approver
+		}
+		return tx.Model(link).Updates(updates).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to approve device",
+		})
+	}
+
+	ipAddr := c.RealIP()
+	metadata := fmt.Sprintf(`{"linkRequestId": "%s", "deviceId": "%s"}`, link.ID, device.ID)
+	db.DB.Create(&models.AuditLog{
+		UserID:     link.UserID,
+		ActionType: "device_link_approved",
+		IPAddress:  &ipAddr,
+		Metadata:   &metadata,
+		Timestamp:  time.Now(),
+	})
+
+	return c.JSON(http.StatusOK, DeviceResponse{
+		ID:         device.ID.String(),
+		DeviceName: device.DeviceName,
+		DeviceType: device.DeviceType,
+		Location:   device.Location,
+		LastSeenAt: device.LastSeenAt.Format(time.RFC3339),
+		IsActive:   device.IsActive,
+	})
+}
+
+// RejectLinkRequest handles POST /api/v1/devices/link-requests/:id/reject
+func (h *DeviceHandler) RejectLinkRequest(c echo.Context) error {
+	link, err := findPendingLinkRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Link request not found or expired",
+		})
+	}
+
+	if err := db.DB.Model(link).Update("status", "rejected").Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to reject device",
+		})
+	}
+
+	ipAddr := c.RealIP()
+	metadata := fmt.Sprintf(`{"linkRequestId": "%s"}`, link.ID)
+	db.DB.Create(&models.AuditLog{
+		UserID:     link.UserID,
+		ActionType: "device_link_rejected",
+		IPAddress:  &ipAddr,
+		Metadata:   &metadata,
+		Timestamp:  time.Now(),
 	})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Device link rejected",
+	})
+}
+
+// findPendingLinkRequest loads the :id link request if it is pending and belongs to the caller
+func findPendingLinkRequest(c echo.Context) (*models.DeviceLinkRequest, error) {
+	linkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	user := auth.CurrentUser(c)
+	var link models.DeviceLinkRequest
+	if err := db.DB.Where("id = ? AND user_id = ? AND status = ? AND expires_at > ?", linkID, user.ID, "pending", time.Now()).
+		First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/db"
 	"github.com/inkless/backend/internal/db/models"
 	"github.com/labstack/echo/v4"
@@ -20,10 +21,11 @@ func NewExportHandler() *ExportHandler {
 // ExportAuditTrail handles GET /api/v1/files/:docHash/audit-trail
 func (h *ExportHandler) ExportAuditTrail(c echo.Context) error {
 	docHash := c.Param("docHash")
+	user := auth.CurrentUser(c)
 
-	// 1. Find the signature metadata to check if document exists and get signer details if needed
+	// 1. Find the caller's signature on this document; only signers may export their trail
 	var sigMetadata models.SignatureMetadata
-	if err := db.DB.Where("doc_hash = ?", docHash).First(&sigMetadata).Error; err != nil {
+	if err := db.DB.Where("doc_hash = ? AND signer_id = ?", docHash, user.ID).First(&sigMetadata).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Document not found"})
 	}
 
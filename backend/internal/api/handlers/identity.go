@@ -1,27 +1,45 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/db"
 	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/identity"
+	"github.com/inkless/backend/internal/ledger"
+	"github.com/inkless/backend/internal/signing"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // IdentityHandler handles identity-related operations
-type IdentityHandler struct{}
+type IdentityHandler struct {
+	provider  identity.Provider
+	jwtSecret string
+}
 
-// NewIdentityHandler creates a new identity handler
-func NewIdentityHandler() *IdentityHandler {
-	return &IdentityHandler{}
+// NewIdentityHandler creates a new identity handler backed by the given vNIN provider
+func NewIdentityHandler(provider identity.Provider, jwtSecret string) *IdentityHandler {
+	return &IdentityHandler{provider: provider, jwtSecret: jwtSecret}
 }
 
 // VerifyRequest represents the NIMC verification request
 type VerifyRequest struct {
 	VNIN         string `json:"vNIN" validate:"required"`
 	ConsentToken string `json:"consent_token" validate:"required"`
+	DevicePubKey string `json:"devicePubKey"`
+
+	// Optional ECDSA P-256 / Ed25519 key used for hybrid signatures
+	ClassicalPubKey string `json:"classicalPubKey"`
 }
 
 // VerifyResponse represents the verification response
@@ -29,10 +47,32 @@ type VerifyResponse struct {
 	Status      string            `json:"status"`
 	DID         string            `json:"did"`
 	UserProfile map[string]string `json:"user_profile,omitempty"`
+
+	// Short-lived grant to exchange for tokens at POST /api/v1/auth/token
+	VerificationToken string `json:"verificationToken,omitempty"`
+	ExpiresAt         string `json:"expiresAt,omitempty"`
+
+	// Set when an existing identity is verified from a new device
+	LinkRequestID string `json:"linkRequestId,omitempty"`
+
+	// Set when the device must prove it holds its key: answer at
+	// POST /api/v1/auth/challenge/response for a known device, or at
+	// POST /api/v1/identity/link-requests/:id/claim once a link is approved
+	Challenge *ChallengeResponse `json:"challenge,omitempty"`
+}
+
+// LinkClaimRequest proves the new device holds the key its link request named
+type LinkClaimRequest struct {
+	ChallengeID string `json:"challengeId" validate:"required"`
+	Algorithm   string `json:"algorithm" validate:"required"`
+	Signature   []byte `json:"signature" validate:"required"`
 }
 
+// deviceLinkTTL is how long an existing device has to approve a new one
+const deviceLinkTTL = 15 * time.Minute
+
 // Verify handles POST /api/v1/identity/verify
-// For MVP, this is a MOCK implementation. Will be replaced with real NIMC API integration.
+// Verifies the vNIN with the configured provider (NIMC or mock).
 func (h *IdentityHandler) Verify(c echo.Context) error {
 	var req VerifyRequest
 	if err := c.Bind(&req); err != nil {
@@ -47,30 +87,373 @@ func (h *IdentityHandler) Verify(c echo.Context) error {
 		})
 	}
 
-	// MOCK: Generate a DID based on the vNIN
-	// In production, this would call the NIMC vNIN API
-	userID := uuid.New()
-	did := "did:inkless:" + userID.String()[:8]
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := h.provider.Verify(ctx, req.VNIN, req.ConsentToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrInvalidVNIN):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{
+				"error": "vNIN could not be verified",
+			})
+		case errors.Is(err, identity.ErrConsentRejected):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Consent token was rejected",
+			})
+		default:
+			log.Printf("[Identity] Verification failed: %v", err)
+			return c.JSON(http.StatusBadGateway, map[string]string{
+				"error": "Identity service unavailable, please try again",
+			})
+		}
+	}
 
-	// Create audit log entry
 	ipAddr := c.RealIP()
+
+	// One vNIN is one identity. An existing one's status is checked before
+	// anything is written: a suspended identity, or one an administrator
+	// deleted, gets nothing
+	user, err := findVerifiedUser(result.VNINHash)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to record identity",
+		})
+	}
+	if user != nil && !auth.IsActive(user) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": auth.InactiveMessage(user),
+		})
+	}
+	created := user == nil
+
+	// Re-verifying an existing identity never hands out a grant by itself:
+	// a device the user already trusts proves it holds its key by answering a
+	// challenge, and any other device must be approved by one that does
+	if !created && req.DevicePubKey == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "devicePubKey is required to verify an existing identity",
+		})
+	}
+	if !created && isKnownDeviceKey(user, req.DevicePubKey) {
+		challenge, err := auth.NewChallenge(user.DIDAddress)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create challenge",
+			})
+		}
+
+		metadata := fmt.Sprintf(`{"newIdentity": false, "mock": %t, "knownDevice": true}`, result.Mock)
+		db.DB.Create(&models.AuditLog{
+			UserID:     user.ID,
+			ActionType: "identity_verify",
+			IPAddress:  &ipAddr,
+			Metadata:   &metadata,
+			Timestamp:  time.Now(),
+		})
+
+		return c.JSON(http.StatusOK, VerifyResponse{
+			Status:    "challenge_required",
+			DID:       user.DIDAddress,
+			Challenge: newChallengeResponse(challenge),
+		})
+	}
+	if !created {
+		link := models.DeviceLinkRequest{
+			UserID:       user.ID,
+			DevicePubKey: req.DevicePubKey,
+			DeviceName:   c.Request().UserAgent(),
+			IPAddress:    ipAddr,
+			Status:       "pending",
+			ExpiresAt:    time.Now().Add(deviceLinkTTL),
+		}
+		if err := db.DB.Create(&link).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create device link request",
+			})
+		}
+
+		metadata := fmt.Sprintf(`{"linkRequestId": "%s"}`, link.ID)
+		db.DB.Create(&models.AuditLog{
+			UserID:     user.ID,
+			ActionType: "device_link_requested",
+			IPAddress:  &ipAddr,
+			Metadata:   &metadata,
+			Timestamp:  time.Now(),
+		})
+
+		return c.JSON(http.StatusAccepted, VerifyResponse{
+			Status:        "device_link_required",
+			DID:           user.DIDAddress,
+			LinkRequestID: link.ID.String(),
+			ExpiresAt:     link.ExpiresAt.Format(time.RFC3339),
+		})
+	}
+
+	user, err = createVerifiedUser(result, req.DevicePubKey, req.ClassicalPubKey)
+	if errors.Is(err, errIdentityExists) {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "This identity was verified concurrently; please try again",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to record identity",
+		})
+	}
+
+	grant, expiresAt, err := auth.IssueVerificationGrant(h.jwtSecret, user.DIDAddress, uuid.Nil)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to issue verification token",
+		})
+	}
+
+	// Create audit log entry
+	metadata := fmt.Sprintf(`{"newIdentity": %t, "mock": %t}`, created, result.Mock)
 	auditLog := models.AuditLog{
-		UserID:     userID,
+		UserID:     user.ID,
 		ActionType: "identity_verify",
 		IPAddress:  &ipAddr,
+		Metadata:   &metadata,
 		Timestamp:  time.Now(),
 	}
 
-	// Note: In a real implementation, we'd check if user exists first
-	// and only create if new, then update the audit log with the real user ID
 	_ = db.DB.Create(&auditLog)
 
+	registerSigner(c, user)
+
 	return c.JSON(http.StatusOK, VerifyResponse{
 		Status: "verified",
-		DID:    did,
+		DID:    user.DIDAddress,
 		UserProfile: map[string]string{
-			"verified_at": time.Now().Format(time.RFC3339),
-			"mock_mode":   "true",
+			"full_name":   user.FullName,
+			"verified_at": result.VerifiedAt.Format(time.RFC3339),
+			"mock_mode":   strconv.FormatBool(result.Mock),
 		},
+		VerificationToken: grant,
+		ExpiresAt:         expiresAt.Format(time.RFC3339),
+	})
+}
+
+// LinkStatus handles GET /api/v1/identity/link-requests/:id
+// Polled by the new device. Once approved, it returns a challenge the
+// device signs with the key it asked to link, to claim its grant at
+// POST /api/v1/identity/link-requests/:id/claim.
+func (h *IdentityHandler) LinkStatus(c echo.Context) error {
+	linkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid link request ID",
+		})
+	}
+
+	var link models.DeviceLinkRequest
+	if err := db.DB.Preload("User").First(&link, linkID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Link request not found",
+		})
+	}
+
+	if link.Status == "pending" && time.Now().After(link.ExpiresAt) {
+		return c.JSON(http.StatusOK, VerifyResponse{Status: "expired", DID: link.User.DIDAddress})
+	}
+	if link.Status != "approved" || link.DeviceID == nil {
+		return c.JSON(http.StatusOK, VerifyResponse{Status: link.Status, DID: link.User.DIDAddress})
+	}
+
+	challenge, err := auth.NewChallenge(link.User.DIDAddress)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create challenge",
+		})
+	}
+
+	return c.JSON(http.StatusOK, VerifyResponse{
+		Status:    "approved",
+		DID:       link.User.DIDAddress,
+		Challenge: newChallengeResponse(challenge),
+	})
+}
+
+// LinkClaim handles POST /api/v1/identity/link-requests/:id/claim
+// Returns a verification token bound to the newly trusted device, once, to
+// the caller that signs the challenge with the key the link request named.
+func (h *IdentityHandler) LinkClaim(c echo.Context) error {
+	var req LinkClaimRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	linkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid link request ID",
+		})
+	}
+	challengeID, err := uuid.Parse(req.ChallengeID)
+	if err != nil || len(req.Signature) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "challengeId and signature are required",
+		})
+	}
+	alg, err := signing.ParseAlgorithm(req.Algorithm)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	var link models.DeviceLinkRequest
+	if err := db.DB.Preload("User").First(&link, linkID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Link request not found",
+		})
+	}
+	if link.Status != "approved" || link.DeviceID == nil {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Link request is " + link.Status,
+		})
+	}
+	if !auth.IsActive(&link.User) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": auth.InactiveMessage(&link.User),
+		})
+	}
+
+	challenge, err := auth.ConsumeChallenge(challengeID)
+	if err != nil || challenge.DIDAddress != link.User.DIDAddress {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid or expired challenge",
+		})
+	}
+
+	ipAddr := c.RealIP()
+	pubKey, err := signing.DecodePublicKey(link.DevicePubKey)
+	if err == nil {
+		err = signing.Verify(alg, pubKey, auth.ChallengeMessage(challenge), req.Signature)
+	}
+	if err != nil {
+		metadata := fmt.Sprintf(`{"linkRequestId": "%s"}`, link.ID)
+		db.DB.Create(&models.AuditLog{
+			UserID:     link.UserID,
+			ActionType: "device_link_claim_failed",
+			IPAddress:  &ipAddr,
+			Metadata:   &metadata,
+			Timestamp:  time.Now(),
+		})
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Challenge verification failed",
+		})
+	}
+
+	// Hand out the grant exactly once
+	result := db.DB.Model(&models.DeviceLinkRequest{}).
+		Where("id = ? AND status = ?", link.ID, "approved").
+		Update("status", "claimed")
+	if result.Error != nil || result.RowsAffected == 0 {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Link request is already claimed",
+		})
+	}
+
+	grant, expiresAt, err := auth.IssueVerificationGrant(h.jwtSecret, link.User.DIDAddress, *link.DeviceID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to issue verification token",
+		})
+	}
+
+	metadata := fmt.Sprintf(`{"linkRequestId": "%s", "deviceId": "%s"}`, link.ID, *link.DeviceID)
+	db.DB.Create(&models.AuditLog{
+		UserID:     link.UserID,
+		ActionType: "device_link_claimed",
+		IPAddress:  &ipAddr,
+		Metadata:   &metadata,
+		Timestamp:  time.Now(),
+	})
+
+	registerSigner(c, &link.User)
+
+	return c.JSON(http.StatusOK, VerifyResponse{
+		Status:            "verified",
+		DID:               link.User.DIDAddress,
+		VerificationToken: grant,
+		ExpiresAt:         expiresAt.Format(time.RFC3339),
 	})
 }
+
+// errIdentityExists is returned when a concurrent verification created the
+// identity first
+var errIdentityExists = errors.New("identity already exists")
+
+// findVerifiedUser returns the user owning a verified vNIN hash, or nil if
+// there is none. Existing identities are never re-keyed, not even legacy
+// rows without a device key: new keys arrive through device linking.
+func findVerifiedUser(vninHash string) (*models.User, error) {
+	var user models.User
+	err := db.DB.Where("vnin_hash = ?", vninHash).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// createVerifiedUser creates the identity for a vNIN verified for the first
+// time, with the offered keys
+func createVerifiedUser(result *identity.Result, devicePubKey, classicalPubKey string) (*models.User, error) {
+	userID := uuid.New()
+	user := models.User{
+		ID:              userID,
+		DIDAddress:      "did:inkless:" + userID.String()[:8],
+		VNINHash:        &result.VNINHash,
+		DevicePubKey:    devicePubKey,
+		ClassicalPubKey: classicalPubKey,
+		FullName:        result.FullName,
+	}
+	if address, err := ledger.AccountAddress(user.DIDAddress); err == nil {
+		user.ChainAddress = address
+	}
+	if err := db.DB.Create(&user).Error; err != nil {
+		// Lost a race with a concurrent verification of the same vNIN
+		if existing, findErr := findVerifiedUser(result.VNINHash); findErr == nil && existing != nil {
+			return nil, errIdentityExists
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// registerSigner makes user's signer account a verified signer on the
+// registry, so it can approve their anchors. It is called wherever a
+// verification grant is issued; a failure is logged and the grant stands.
+func registerSigner(c echo.Context, user *models.User) {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := queueSignerChange(c, tx, user, true, anchoring.SignerReasonVerified)
+		return err
+	})
+	switch {
+	case errors.Is(err, anchoring.ErrNoSignerAccount):
+	case err != nil:
+		log.Printf("[Identity] Failed to queue signer registration for %s: %v", user.DIDAddress, err)
+	default:
+		anchoring.Notify()
+	}
+}
+
+// isKnownDeviceKey reports whether key is the user's primary key or belongs to an active linked device
+func isKnownDeviceKey(user *models.User, key string) bool {
+	if user.DevicePubKey == key {
+		return true
+	}
+	var count int64
+	db.DB.Model(&models.TrustedDevice{}).
+		Where("user_id = ? AND public_key = ? AND is_active = ?", user.ID, key, true).
+		Count(&count)
+	return count > 0
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/db"
 	"github.com/inkless/backend/internal/db/models"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // OfflineHandler handles offline signature synchronization
@@ -22,9 +25,10 @@ func NewOfflineHandler() *OfflineHandler {
 type OfflineSyncItem struct {
 	DocHash      string    `json:"docHash" validate:"required"`
 	PQCSignature []byte    `json:"pqcSignature" validate:"required"`
+	Algorithm    string    `json:"algorithm" validate:"required"` // ml-dsa-44, ml-dsa-65 or ml-dsa-87
 	HardwareID   string    `json:"hardwareID" validate:"required"`
 	LocalTS      time.Time `json:"localTimestamp" validate:"required"`
-	SignerDID    string    `json:"signerDID" validate:"required"`
+	SignerDID    string    `json:"signerDID"` // Optional; must match the authenticated caller if set
 }
 
 // SyncRequest represents the offline sync request
@@ -38,6 +42,10 @@ type SyncResult struct {
 	Status  string `json:"status"` // synced, failed, already_exists
 	TxHash  string `json:"txHash,omitempty"`
 	Error   string `json:"error,omitempty"`
+	// Set once queued; poll GET /api/v1/signatures/:docId/status for the ledger result
+	DocID        string `json:"docId,omitempty"`
+	AnchorStatus string `json:"anchorStatus,omitempty"`
+	Code         string `json:"code,omitempty"` // Machine-readable rejection code, e.g. invalid_signature
 }
 
 // SyncResponse represents the sync response
@@ -64,17 +72,44 @@ func (h *OfflineHandler) Sync(c echo.Context) error {
 		})
 	}
 
+	user := auth.CurrentUser(c)
+
 	results := make([]SyncResult, 0, len(req.Signatures))
 	synced := 0
 	failed := 0
 
+	now := time.Now()
 	for _, sig := range req.Signatures {
 		result := SyncResult{DocHash: sig.DocHash}
 
-		// Check if already exists
+		if sig.SignerDID != "" && sig.SignerDID != user.DIDAddress {
+			result.Status = "failed"
+			result.Error = "signerDID does not match the authenticated user"
+			failed++
+			results = append(results, result)
+			continue
+		}
+
+		// Offline signatures get the same ML-DSA check as online anchors
+		alg, keyID, err := verifyPQCSignature(user, sig.Algorithm, sig.DocHash, sig.PQCSignature)
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			var rejection *signatureRejection
+			if errors.As(err, &rejection) {
+				result.Code = rejection.code
+			}
+			failed++
+			results = append(results, result)
+			continue
+		}
+
+		// Check if this signer already signed the document
 		var existing models.SignatureMetadata
-		if err := db.DB.Where("doc_hash = ?", sig.DocHash).First(&existing).Error; err == nil {
+		if err := db.DB.Where("doc_hash = ? AND signer_id = ?", sig.DocHash, user.ID).First(&existing).Error; err == nil {
 			result.Status = "already_exists"
+			result.DocID = existing.ID.String()
+			result.AnchorStatus = existing.Status
 			if existing.LedgerTxHash != nil {
 				result.TxHash = *existing.LedgerTxHash
 			}
@@ -82,55 +117,56 @@ func (h *OfflineHandler) Sync(c echo.Context) error {
 			continue
 		}
 
-		// Store offline signature for processing
+		// Store the offline signature and queue it for the ledger alongside its
+		// pending metadata; the anchoring worker does the submission
 		offlineSig := models.OfflineSignature{
+			UserID:       &user.ID,
 			DocHash:      sig.DocHash,
 			PQCSignature: sig.PQCSignature,
+			Algorithm:    string(alg),
 			HardwareID:   sig.HardwareID,
 			LocalTS:      sig.LocalTS,
-			SyncStatus:   "pending",
-		}
-
-		if err := db.DB.Create(&offlineSig).Error; err != nil {
-			result.Status = "failed"
-			result.Error = "Failed to store signature"
-			failed++
-			results = append(results, result)
-			continue
+			SyncStatus:   "synced",
+			SyncedAt:     &now,
 		}
-
-		// TODO: Queue for blockchain submission via Redis
-		// For MVP, mock immediate anchoring
-		mockTxHash := "0x" + uuid.New().String()[:32]
-		now := time.Now()
-
 		sigMetadata := models.SignatureMetadata{
 			DocHash:      sig.DocHash,
-			SignerID:     uuid.New(), // In production, look up from DID
-			LedgerTxHash: &mockTxHash,
-			Status:       "anchored",
+			SignerID:     user.ID,
+			Status:       anchoring.SignaturePending,
 			HardwareID:   sig.HardwareID,
+			Algorithm:    string(alg),
+			KeyID:        keyID,
+			PQCSignature: sig.PQCSignature,
 		}
 
-		if err := db.DB.Create(&sigMetadata).Error; err != nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&offlineSig).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&sigMetadata).Error; err != nil {
+				return err
+			}
+			_, err := anchoring.Enqueue(tx, &sigMetadata, user.DIDAddress, sig.PQCSignature)
+			return err
+		})
+		if err != nil {
 			result.Status = "failed"
-			result.Error = "Failed to anchor signature"
+			result.Error = "Failed to store signature"
 			failed++
 		} else {
-			// Update offline signature status
-			db.DB.Model(&offlineSig).Updates(map[string]interface{}{
-				"sync_status": "synced",
-				"synced_at":   now,
-			})
-
 			result.Status = "synced"
-			result.TxHash = mockTxHash
+			result.DocID = sigMetadata.ID.String()
+			result.AnchorStatus = sigMetadata.Status
 			synced++
 		}
 
 		results = append(results, result)
 	}
 
+	if synced > 0 {
+		anchoring.Notify()
+	}
+
 	return c.JSON(http.StatusOK, SyncResponse{
 		Processed: len(req.Signatures),
 		Synced:    synced,
@@ -142,8 +178,10 @@ func (h *OfflineHandler) Sync(c echo.Context) error {
 // GetPendingCount handles GET /api/v1/offline/pending
 // Returns count of pending offline signatures for the user
 func (h *OfflineHandler) GetPendingCount(c echo.Context) error {
+	user := auth.CurrentUser(c)
+
 	var count int64
-	db.DB.Model(&models.OfflineSignature{}).Where("sync_status = ?", "pending").Count(&count)
+	db.DB.Model(&models.OfflineSignature{}).Where("user_id = ? AND sync_status = ?", user.ID, "pending").Count(&count)
 
 	return c.JSON(http.StatusOK, map[string]int64{
 		"pending": count,
@@ -5,6 +5,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/db"
 	"github.com/inkless/backend/internal/db/models"
 )
@@ -35,22 +36,11 @@ type UpdatePreferencesRequest struct {
 
 // GetPreferences handles GET /api/v1/preferences
 func (h *PreferencesHandler) GetPreferences(c echo.Context) error {
-	// Get or create demo user
-	var user models.User
-	result := db.DB.Where("did_address = ?", "did:inkless:demo").FirstOrCreate(&user, models.User{
-		DIDAddress: "did:inkless:demo",
-		FullName:   "Demo User",
-		Email:      "demo@inkless.app",
-	})
-	if result.Error != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to get or create user",
-		})
-	}
+	user := auth.CurrentUser(c)
 
 	// Get or create preferences
 	var prefs models.UserPreferences
-	result = db.DB.FirstOrCreate(&prefs, models.UserPreferences{
+	result := db.DB.FirstOrCreate(&prefs, models.UserPreferences{
 		UserID: user.ID,
 	})
 
@@ -77,17 +67,7 @@ func (h *PreferencesHandler) UpdatePreferences(c echo.Context) error {
 		})
 	}
 
-	// Get or create demo user
-	var user models.User
-	if err := db.DB.Where("did_address = ?", "did:inkless:demo").FirstOrCreate(&user, models.User{
-		DIDAddress: "did:inkless:demo",
-		FullName:   "Demo User",
-		Email:      "demo@inkless.app",
-	}).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to get or create user",
-		})
-	}
+	user := auth.CurrentUser(c)
 
 	// Get or create preferences
 	var prefs models.UserPreferences
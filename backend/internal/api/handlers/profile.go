@@ -5,6 +5,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/db"
 	"github.com/inkless/backend/internal/db/models"
 )
@@ -19,10 +20,11 @@ func NewProfileHandler() *ProfileHandler {
 
 // ProfileResponse represents the user profile in API responses
 type ProfileResponse struct {
-	DID        string `json:"did"`
-	Name       string `json:"name"`
-	Email      string `json:"email,omitempty"`
-	IsVerified bool   `json:"isVerified"`
+	DID          string `json:"did"`
+	ChainAddress string `json:"chainAddress,omitempty"` // Signer account that approves the user's anchors; its key is held by the server, not the device
+	Name         string `json:"name"`
+	Email        string `json:"email,omitempty"`
+	IsVerified   bool   `json:"isVerified"`
 }
 
 // UpdateProfileRequest represents the request to update profile
@@ -33,18 +35,7 @@ type UpdateProfileRequest struct {
 
 // GetProfile handles GET /api/v1/profile
 func (h *ProfileHandler) GetProfile(c echo.Context) error {
-	// For MVP, we use a demo user. In production, this comes from auth middleware.
-	var user models.User
-	result := db.DB.FirstOrCreate(&user, models.User{
-		DIDAddress:   "did:inkless:demo",
-		DevicePubKey: "demo_pub_key",
-	})
-
-	if result.Error != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to fetch profile",
-		})
-	}
+	user := auth.CurrentUser(c)
 
 	// Set default name if empty
 	name := user.FullName
@@ -57,10 +48,11 @@ func (h *ProfileHandler) GetProfile(c echo.Context) error {
 	db.DB.Model(&models.SignatureMetadata{}).Where("signer_id = ?", user.ID).Count(&sigCount)
 
 	return c.JSON(http.StatusOK, ProfileResponse{
-		DID:        user.DIDAddress,
-		Name:       name,
-		Email:      user.Email,
-		IsVerified: sigCount > 0,
+		DID:          user.DIDAddress,
+		ChainAddress: user.ChainAddress,
+		Name:         name,
+		Email:        user.Email,
+		IsVerified:   sigCount > 0,
 	})
 }
 
@@ -73,18 +65,7 @@ func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
 		})
 	}
 
-	// For MVP, update the demo user
-	var user models.User
-	result := db.DB.Where("did_address = ?", "did:inkless:demo").FirstOrCreate(&user, models.User{
-		DIDAddress: "did:inkless:demo",
-		FullName:   "Demo User",
-		Email:      "demo@inkless.app",
-	})
-	if result.Error != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to get or create user",
-		})
-	}
+	user := auth.CurrentUser(c)
 
 	// Update fields
 	updates := map[string]interface{}{}
@@ -96,7 +77,7 @@ func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
 	}
 
 	if len(updates) > 0 {
-		if err := db.DB.Model(&user).Updates(updates).Error; err != nil {
+		if err := db.DB.Model(user).Updates(updates).Error; err != nil {
 			return c.JSON(http.StatusInternalServerError, map[string]string{
 				"error": "Failed to update profile",
 			})
@@ -104,15 +85,33 @@ func (h *ProfileHandler) UpdateProfile(c echo.Context) error {
 	}
 
 	// Reload user
-	db.DB.First(&user, user.ID)
+	db.DB.First(user, user.ID)
 
 	var sigCount int64
 	db.DB.Model(&models.SignatureMetadata{}).Where("signer_id = ?", user.ID).Count(&sigCount)
 
 	return c.JSON(http.StatusOK, ProfileResponse{
-		DID:        user.DIDAddress,
-		Name:       user.FullName,
-		Email:      user.Email,
-		IsVerified: sigCount > 0,
+		DID:          user.DIDAddress,
+		ChainAddress: user.ChainAddress,
+		Name:         user.FullName,
+		Email:        user.Email,
+		IsVerified:   sigCount > 0,
+	})
+}
+
+// DeleteAccount handles DELETE /api/v1/profile
+// Closes the caller's account, signing out every device and removing their
+// verified signer. Signatures already made stay verifiable.
+func (h *ProfileHandler) DeleteAccount(c echo.Context) error {
+	user := auth.CurrentUser(c)
+
+	if err := deactivateUser(c, user, auth.UserDeleted, "closed by user"); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to delete account",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Account deleted",
 	})
 }
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/auth"
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Revocation reasons accepted by Revoke
+var revocationReasons = map[string]bool{
+	"ndpa_erasure":    true, // NDPA 2023 erasure request
+	"key_compromise":  true,
+	"signed_in_error": true,
+	"superseded":      true,
+	"other":           true,
+}
+
+// RevokeRequest is the body of POST /api/v1/signatures/:id/revoke
+type RevokeRequest struct {
+	Reason string `json:"reason"` // ndpa_erasure, key_compromise, signed_in_error, superseded, other
+	Note   string `json:"note"`   // Optional free text, at most 500 characters
+}
+
+// RevocationNone is the revocation status of a signature with no on-chain record to revoke
+const RevocationNone = "none"
+
+// Revoke refuses a signature already revoked, or one whose anchor is in flight
+var (
+	errAlreadyRevoked = errors.New("signature already revoked")
+	errAnchorInFlight = errors.New("signature is being anchored")
+)
+
+// RevokeResponse reports a revocation. The row is revoked at once; the
+// on-chain record and its redundant copies are revoked by the anchor queue;
+// verification responses show the signer's revocationTxHash and each copy's
+// revocation status.
+type RevokeResponse struct {
+	DocID            string `json:"docId"`
+	DocHash          string `json:"docHash"`
+	Status           string `json:"status"`
+	Reason           string `json:"reason"`
+	RevokedAt        string `json:"revokedAt"`
+	RevocationStatus string `json:"revocationStatus"` // queued, or none when there is no on-chain record or copy to revoke
+}
+
+// Revoke handles POST /api/v1/signatures/:id/revoke
+// Only the signer or an admin may revoke. The row is revoked here and an
+// anchored signature's on-chain revocation is queued in the same transaction,
+// for the worker to submit and watch like any anchor.
+func (h *SignatureHandler) Revoke(c echo.Context) error {
+	user := auth.CurrentUser(c)
+
+	sigID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid signature ID",
+		})
+	}
+
+	var req RevokeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+	if !revocationReasons[req.Reason] {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "reason must be one of ndpa_erasure, key_compromise, signed_in_error, superseded, other",
+		})
+	}
+	if len(req.Note) > 500 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "note must be at most 500 characters",
+		})
+	}
+
+	var sig models.SignatureMetadata
+	if err := db.DB.Preload("Signer").Where("id = ?", sigID).First(&sig).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Signature not found",
+		})
+	}
+	if sig.SignerID != user.ID && !auth.IsAdmin(user) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Only the signer or an admin can revoke this signature",
+		})
+	}
+
+	// Lock the row and its anchor jobs so a worker can't claim one between
+	// the check and the update: a tx in flight, on any network, would
+	// re-create the record after it's revoked
+	var revocations []models.AnchorJob
+	now := time.Now()
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Signer").Where("id = ?", sigID).First(&sig).Error; err != nil {
+			return err
+		}
+		if sig.Status == anchoring.SignatureRevoked {
+			return errAlreadyRevoked
+		}
+		var jobs []models.AnchorJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("signature_id = ? AND kind = ?", sig.ID, anchoring.JobKindSignature).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		for _, j := range jobs {
+			if j.Status == anchoring.JobProcessing || j.Status == anchoring.JobSubmitted {
+				return errAnchorInFlight
+			}
+		}
+
+		// Queued before the row is flipped, while it still says whether it was
+		// anchored. Redundant copies are revoked too, but the row follows its
+		// own network.
+		var err error
+		if revocations, err = anchoring.EnqueueRevocation(tx, &sig, sig.Signer.DIDAddress); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&sig).Updates(map[string]interface{}{
+			"status":            anchoring.SignatureRevoked,
+			"revoked_at":        now,
+			"revoked_by":        user.ID,
+			"revocation_reason": req.Reason,
+			"revocation_note":   req.Note,
+		}).Error; err != nil {
+			return err
+		}
+		// Never anchor a signature revoked while still queued
+		return tx.Model(&models.AnchorJob{}).
+			Where("signature_id = ? AND kind = ? AND status = ?", sig.ID, anchoring.JobKindSignature, anchoring.JobQueued).
+			Updates(map[string]interface{}{
+				"status":     anchoring.JobFailed,
+				"last_error": "signature revoked",
+			}).Error
+	})
+	switch {
+	case errors.Is(err, errAlreadyRevoked):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Signature already revoked",
+		})
+	case errors.Is(err, errAnchorInFlight):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Signature is being anchored; retry once it is anchored",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to revoke signature",
+		})
+	}
+
+	resp := RevokeResponse{
+		DocID:            sig.ID.String(),
+		DocHash:          sig.DocHash,
+		Status:           anchoring.SignatureRevoked,
+		Reason:           req.Reason,
+		RevokedAt:        now.Format(time.RFC3339),
+		RevocationStatus: RevocationNone,
+	}
+	jobIDs := make([]uuid.UUID, len(revocations))
+	for i, job := range revocations {
+		jobIDs[i] = job.ID
+	}
+	if len(revocations) > 0 {
+		anchoring.Notify()
+		resp.RevocationStatus = anchoring.JobQueued
+	}
+
+	ipAddr := c.RealIP()
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"signatureId":      sig.ID,
+		"docHash":          sig.DocHash,
+		"reason":           req.Reason,
+		"note":             req.Note,
+		"revokedBy":        user.ID,
+		"revocationJobIds": jobIDs,
+	})
+	metadataStr := string(metadata)
+	db.DB.Create(&models.AuditLog{
+		UserID:     sig.SignerID,
+		ActionType: "signature_revoke",
+		IPAddress:  &ipAddr,
+		Metadata:   &metadataStr,
+		Timestamp:  now,
+	})
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -1,44 +1,66 @@
 package handlers
 
 import (
-	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/db"
 	"github.com/inkless/backend/internal/db/models"
 	"github.com/inkless/backend/internal/ledger"
+	"github.com/inkless/backend/internal/merkle"
+	"github.com/inkless/backend/internal/signing"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // SignatureHandler handles signature-related operations
-type SignatureHandler struct{}
+type SignatureHandler struct {
+	requireChain bool // Verify reports valid only once the chain confirms every signer
+}
 
 // NewSignatureHandler creates a new signature handler
-func NewSignatureHandler() *SignatureHandler {
-	return &SignatureHandler{}
+func NewSignatureHandler(requireChain bool) *SignatureHandler {
+	return &SignatureHandler{requireChain: requireChain}
 }
 
 // AnchorRequest represents the signature anchoring request
 type AnchorRequest struct {
-	DocHash          string `json:"docHash" validate:"required"`
-	PQCSignature     []byte `json:"pqcSignature" validate:"required"`
-	HardwareID       string `json:"hardwareID" validate:"required"`
-	SignerDID        string `json:"signerDID" validate:"required"`
-	DocumentCategory string `json:"documentCategory"`
-	FileName         string `json:"fileName"`
-	FileSize         string `json:"fileSize"`
-	MimeType         string `json:"mimeType"`
+	DocHash      string `json:"docHash" validate:"required"`
+	PQCSignature []byte `json:"pqcSignature" validate:"required"`
+	Algorithm    string `json:"algorithm" validate:"required"` // ml-dsa-44, ml-dsa-65 or ml-dsa-87
+	// Optional classical half of a hybrid signature over the same DocHash
+	ClassicalSignature []byte `json:"classicalSignature,omitempty"`
+	ClassicalAlgorithm string `json:"classicalAlgorithm,omitempty"` // ecdsa-p256 or ed25519
+	HardwareID         string `json:"hardwareID" validate:"required"`
+	SignerDID          string `json:"signerDID"` // Optional; must match the authenticated caller if set
+	DocumentCategory   string `json:"documentCategory"`
+	FileName           string `json:"fileName"`
+	FileSize           string `json:"fileSize"`
+	MimeType           string `json:"mimeType"`
 }
 
 // AnchorResponse represents the anchoring response
 type AnchorResponse struct {
-	TxHash     string `json:"txHash"`
-	AnchoredAt string `json:"anchoredAt"`
-	DocID      string `json:"docId"`
-	Status     string `json:"status"`
+	TxHash          string `json:"txHash,omitempty"`     // Set once the anchoring worker submits it
+	AnchoredAt      string `json:"anchoredAt,omitempty"` // Set once the anchoring worker submits it
+	DocID           string `json:"docId"`
+	Status          string `json:"status"`    // pending until the ledger accepts it
+	StatusURL       string `json:"statusUrl"` // Poll for anchored/failed
+	Algorithm       string `json:"algorithm"`
+	KeyID           string `json:"keyId"`
+	AlgorithmStatus string `json:"algorithmStatus"` // active or deprecated
+
+	// Set for hybrid signatures
+	ClassicalAlgorithm string `json:"classicalAlgorithm,omitempty"`
+	ClassicalKeyID     string `json:"classicalKeyId,omitempty"`
 }
 
 // Anchor handles POST /api/v1/signatures/anchor
@@ -61,112 +83,396 @@ func (h *SignatureHandler) Anchor(c echo.Context) error {
 		req.DocumentCategory = "general_contract"
 	}
 
-	// Find or create the user first (needed for duplicate check)
-	var user models.User
-	if err := db.DB.Where("d_id_address = ?", req.SignerDID).First(&user).Error; err != nil {
-		// Create a mock user if not found
-		mockVNINHash := "mock_vnin_hash_" + uuid.New().String()[:8]
-		user = models.User{
-			DIDAddress:   req.SignerDID,
-			VNINHash:     &mockVNINHash,
-			DevicePubKey: "mock_pubkey_" + req.HardwareID,
-		}
-		if err := db.DB.Create(&user).Error; err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to create user",
-			})
+	// The signer is always the authenticated caller
+	user := auth.CurrentUser(c)
+	if req.SignerDID != "" && req.SignerDID != user.DIDAddress {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "signerDID does not match the authenticated user",
+		})
+	}
+
+	// Verify the ML-DSA signature over DocHash before anything touches the ledger
+	alg, keyID, err := verifyPQCSignature(user, req.Algorithm, req.DocHash, req.PQCSignature)
+	if err != nil {
+		return signatureError(c, err)
+	}
+
+	// A hybrid anchor must carry a valid classical signature too, and the
+	// ledger commits to both parts rather than the ML-DSA signature alone
+	anchored := req.PQCSignature
+	var classicalAlg signing.Algorithm
+	var classicalKeyID string
+	if len(req.ClassicalSignature) > 0 || req.ClassicalAlgorithm != "" {
+		classicalAlg, classicalKeyID, err = verifyClassicalSignature(user, req.ClassicalAlgorithm, req.DocHash, req.ClassicalSignature)
+		if err != nil {
+			return signatureError(c, err)
 		}
+		anchored = signing.EncodeComposite(
+			signing.Component{Algorithm: alg, Signature: req.PQCSignature},
+			signing.Component{Algorithm: classicalAlg, Signature: req.ClassicalSignature},
+		)
 	}
 
 	// Check if THIS SIGNER has already signed THIS document (allow multi-party signing)
 	var existingSig models.SignatureMetadata
 	if err := db.DB.Where("doc_hash = ? AND signer_id = ?", req.DocHash, user.ID).First(&existingSig).Error; err == nil {
-		return c.JSON(http.StatusConflict, map[string]string{
+		resp := map[string]string{
 			"error":  "You have already signed this document",
-			"txHash": *existingSig.LedgerTxHash,
-		})
-	}
-
-	// Submit to blockchain (real or mock)
-	var txHash string
-	anchoredAt := time.Now()
-
-	if ledger.IsConnected() {
-		// Real blockchain: submit to InklessRegistry contract
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		realTxHash, err := ledger.Global.AnchorSignature(ctx, req.DocHash, req.PQCSignature, req.HardwareID)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": fmt.Sprintf("Blockchain anchoring failed: %v", err),
-			})
+			"docId":  existingSig.ID.String(),
+			"status": existingSig.Status,
+		}
+		if existingSig.LedgerTxHash != nil {
+			resp["txHash"] = *existingSig.LedgerTxHash
 		}
-		txHash = realTxHash
-	} else {
-		// Fallback: generate mock transaction hash
-		txHash = "0x" + uuid.New().String()[:32]
+		return c.JSON(http.StatusConflict, resp)
 	}
 
-	// User already created/fetched above before duplicate check
-
-	// Create signature metadata
+	// Record the signature as pending and queue it for the ledger in one
+	// transaction; the anchoring worker submits it and retries on failure
 	sigMetadata := models.SignatureMetadata{
-		DocHash:          req.DocHash,
-		SignerID:         user.ID, // Use the actual user ID
-		DocumentCategory: req.DocumentCategory,
-		FileName:         req.FileName,
-		FileSize:         req.FileSize,
-		MimeType:         req.MimeType,
-		LedgerTxHash:     &txHash,
-		Status:           "anchored",
-		HardwareID:       req.HardwareID,
-	}
-
-	if err := db.DB.Create(&sigMetadata).Error; err != nil {
+		DocHash:            req.DocHash,
+		SignerID:           user.ID, // Use the actual user ID
+		DocumentCategory:   req.DocumentCategory,
+		FileName:           req.FileName,
+		FileSize:           req.FileSize,
+		MimeType:           req.MimeType,
+		Status:             anchoring.SignaturePending,
+		HardwareID:         req.HardwareID,
+		Algorithm:          string(alg),
+		KeyID:              keyID,
+		PQCSignature:       req.PQCSignature,
+		ClassicalAlgorithm: string(classicalAlg),
+		ClassicalKeyID:     classicalKeyID,
+		ClassicalSignature: req.ClassicalSignature,
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&sigMetadata).Error; err != nil {
+			return err
+		}
+		_, err := anchoring.Enqueue(tx, &sigMetadata, user.DIDAddress, anchored)
+		return err
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to record signature",
 		})
 	}
+	anchoring.Notify()
 
 	// Create audit log
 	ipAddr := c.RealIP()
-	metadata := fmt.Sprintf(`{"docHash": "%s", "hardwareID": "%s", "category": "%s"}`, req.DocHash, req.HardwareID, req.DocumentCategory)
+	metadata := fmt.Sprintf(`{"docHash": "%s", "hardwareID": "%s", "category": "%s", "algorithm": "%s", "keyId": "%s", "classicalAlgorithm": "%s"}`, req.DocHash, req.HardwareID, req.DocumentCategory, alg, keyID, classicalAlg)
 	auditLog := models.AuditLog{
 		UserID:     sigMetadata.SignerID,
 		ActionType: "signature_anchor",
 		IPAddress:  &ipAddr,
 		Metadata:   &metadata,
-		Timestamp:  anchoredAt,
+		Timestamp:  sigMetadata.CreatedAt,
 	}
 	db.DB.Create(&auditLog)
 
-	return c.JSON(http.StatusOK, AnchorResponse{
-		TxHash:     txHash,
-		AnchoredAt: anchoredAt.Format(time.RFC3339),
-		DocID:      sigMetadata.ID.String(),
-		Status:     "anchored",
+	return c.JSON(http.StatusAccepted, AnchorResponse{
+		DocID:              sigMetadata.ID.String(),
+		Status:             sigMetadata.Status,
+		StatusURL:          "/api/v1/signatures/" + sigMetadata.ID.String() + "/status",
+		Algorithm:          string(alg),
+		KeyID:              keyID,
+		AlgorithmStatus:    string(signing.DefaultRegistry.Status(alg)),
+		ClassicalAlgorithm: string(classicalAlg),
+		ClassicalKeyID:     classicalKeyID,
+	})
+}
+
+// AnchorStatusResponse reports where a signature is in the anchoring pipeline
+type AnchorStatusResponse struct {
+	DocID         string `json:"docId"`
+	DocHash       string `json:"docHash"`
+	Status        string `json:"status"` // pending, submitted, anchored, failed
+	TxHash        string `json:"txHash,omitempty"`
+	LedgerBackend string `json:"ledgerBackend,omitempty"` // besu or simulated, once submitted
+	LedgerNetwork string `json:"ledgerNetwork,omitempty"` // Configured network the signature is anchored on
+	AnchoredAt    string `json:"anchoredAt,omitempty"`    // Block timestamp
+	BlockNumber   uint64 `json:"blockNumber,omitempty"`
+	GasUsed       uint64 `json:"gasUsed,omitempty"`
+	Confirmations int    `json:"confirmations"`
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt string `json:"nextAttemptAt,omitempty"`
+	LastError     string `json:"lastError,omitempty"`
+}
+
+// Status handles GET /api/v1/signatures/:id/status
+// Clients poll this after Anchor until the status is final.
+func (h *SignatureHandler) Status(c echo.Context) error {
+	status, err := loadAnchorStatus(c)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Signature not found",
+		})
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// Events handles GET /api/v1/signatures/:id/events
+// Streams status changes as server-sent events until the status is final.
+func (h *SignatureHandler) Events(c echo.Context) error {
+	status, err := loadAnchorStatus(c)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Signature not found",
+		})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	var last AnchorStatusResponse
+	for {
+		if *status != last {
+			data, _ := json.Marshal(status)
+			fmt.Fprintf(res, "event: status\ndata: %s\n\n", data)
+			res.Flush()
+			last = *status
+		}
+		if anchoring.IsFinal(status.Status) {
+			return nil
+		}
+
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if status, err = loadAnchorStatus(c); err != nil {
+			return nil
+		}
+	}
+}
+
+// loadAnchorStatus resolves :id to one of the caller's signatures
+func loadAnchorStatus(c echo.Context) (*AnchorStatusResponse, error) {
+	sigID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	return findAnchorStatus(sigID, auth.CurrentUser(c).ID)
+}
+
+func findAnchorStatus(sigID, signerID uuid.UUID) (*AnchorStatusResponse, error) {
+	var sig models.SignatureMetadata
+	if err := db.DB.Where("id = ? AND signer_id = ?", sigID, signerID).First(&sig).Error; err != nil {
+		return nil, err
+	}
+
+	status := &AnchorStatusResponse{
+		DocID:         sig.ID.String(),
+		DocHash:       sig.DocHash,
+		Status:        sig.Status,
+		LedgerBackend: sig.LedgerBackend,
+		LedgerNetwork: sig.LedgerNetwork,
+		Confirmations: sig.Confirmations,
+	}
+	if sig.LedgerTxHash != nil {
+		status.TxHash = *sig.LedgerTxHash
+	}
+	if sig.BlockNumber != nil {
+		status.BlockNumber = *sig.BlockNumber
+	}
+	if sig.BlockTimestamp != nil {
+		status.AnchoredAt = sig.BlockTimestamp.Format(time.RFC3339)
+	}
+	if sig.GasUsed != nil {
+		status.GasUsed = *sig.GasUsed
+	}
+
+	var job models.AnchorJob
+	if db.DB.Where("signature_id = ? AND kind = ? AND NOT redundant", sig.ID, anchoring.JobKindSignature).First(&job).Error == nil {
+		status.Attempts = job.Attempts
+		if job.Status == anchoring.JobQueued && job.Attempts > 0 {
+			status.NextAttemptAt = job.NextAttemptAt.Format(time.RFC3339)
+		}
+		if job.LastError != nil {
+			status.LastError = *job.LastError
+		}
+	}
+
+	return status, nil
+}
+
+// Error codes returned alongside signature rejections
+const (
+	errCodeInvalidDocHash       = "invalid_doc_hash"
+	errCodeUnsupportedAlgorithm = "unsupported_algorithm"
+	errCodeForbiddenAlgorithm   = "forbidden_algorithm"
+	errCodeInvalidSignature     = "invalid_signature"
+)
+
+// signatureRejection carries the client-facing error code for a failed check
+type signatureRejection struct {
+	code string
+	err  error
+}
+
+func (r *signatureRejection) Error() string { return r.err.Error() }
+func (r *signatureRejection) Unwrap() error { return r.err }
+
+// verifyPQCSignature checks a post-quantum signature over the raw DocHash
+// digest against the signer's registered device keys. It returns the
+// algorithm and the KeyID of the key that verified it.
+func verifyPQCSignature(user *models.User, algorithm, docHash string, signature []byte) (signing.Algorithm, string, error) {
+	return verifyDocSignature(user, algorithm, true, docHash, signature)
+}
+
+// verifyClassicalSignature checks the classical half of a hybrid signature
+// over the same DocHash digest
+func verifyClassicalSignature(user *models.User, algorithm, docHash string, signature []byte) (signing.Algorithm, string, error) {
+	if algorithm == "" || len(signature) == 0 {
+		return "", "", &signatureRejection{code: errCodeInvalidSignature, err: fmt.Errorf("classicalSignature and classicalAlgorithm must be sent together")}
+	}
+	return verifyDocSignature(user, algorithm, false, docHash, signature)
+}
+
+func verifyDocSignature(user *models.User, algorithm string, postQuantum bool, docHash string, signature []byte) (signing.Algorithm, string, error) {
+	alg, err := signing.ParseAlgorithm(algorithm)
+	if err != nil {
+		return "", "", &signatureRejection{code: errCodeUnsupportedAlgorithm, err: err}
+	}
+	spec, _ := signing.DefaultRegistry.Lookup(alg)
+	if spec.PostQuantum != postQuantum {
+		kind := "a post-quantum"
+		if !postQuantum {
+			kind = "a classical"
+		}
+		return "", "", &signatureRejection{code: errCodeUnsupportedAlgorithm, err: fmt.Errorf("%s is not %s algorithm", alg, kind)}
+	}
+
+	digest, err := hex.DecodeString(strings.TrimPrefix(docHash, "0x"))
+	if err != nil || len(digest) == 0 {
+		return "", "", &signatureRejection{code: errCodeInvalidDocHash, err: fmt.Errorf("docHash must be a hex-encoded digest")}
+	}
+
+	keyID, err := verifyDeviceKeySignature(user, alg, digest, signature)
+	switch {
+	case errors.Is(err, signing.ErrAlgorithmForbidden):
+		return "", "", &signatureRejection{code: errCodeForbiddenAlgorithm, err: err}
+	case err != nil:
+		return "", "", &signatureRejection{code: errCodeInvalidSignature, err: signing.ErrInvalidSignature}
+	}
+
+	return alg, keyID, nil
+}
+
+// signatureError renders a verifyPQCSignature or verifyClassicalSignature failure
+func signatureError(c echo.Context, err error) error {
+	var rejection *signatureRejection
+	if !errors.As(err, &rejection) {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to verify signature",
+		})
+	}
+
+	status := http.StatusBadRequest
+	if rejection.code == errCodeInvalidSignature {
+		status = http.StatusUnprocessableEntity
+	}
+	return c.JSON(status, map[string]string{
+		"error": rejection.Error(),
+		"code":  rejection.code,
 	})
 }
 
 // VerifyResponse represents the verification response
 type SignerInfo struct {
-	DID       string `json:"did"`
-	Timestamp string `json:"timestamp"`
-	TxHash    string `json:"txHash,omitempty"`
+	DID             string `json:"did"`
+	Account         string `json:"account,omitempty"` // Signer account derived for the DID
+	Timestamp       string `json:"timestamp"`
+	TxHash          string `json:"txHash,omitempty"`
+	LedgerBackend   string `json:"ledgerBackend,omitempty"` // Ledger that anchored it: besu, simulated, or mock
+	LedgerNetwork   string `json:"ledgerNetwork,omitempty"` // Configured network it is anchored on
+	Status          string `json:"status"`                  // pending, submitted, anchored, failed, revoked, mismatch
+	BlockNumber     uint64 `json:"blockNumber,omitempty"`
+	BlockTimestamp  string `json:"blockTimestamp,omitempty"`
+	Confirmations   int    `json:"confirmations"`
+	Algorithm       string `json:"algorithm,omitempty"`
+	KeyID           string `json:"keyId,omitempty"`
+	AlgorithmStatus string `json:"algorithmStatus,omitempty"` // active, deprecated, forbidden
+	PQCSignature    []byte `json:"pqcSignature,omitempty"`
+
+	// Classical half of a hybrid signature
+	ClassicalAlgorithm       string `json:"classicalAlgorithm,omitempty"`
+	ClassicalKeyID           string `json:"classicalKeyId,omitempty"`
+	ClassicalSignature       []byte `json:"classicalSignature,omitempty"`
+	ClassicalAlgorithmStatus string `json:"classicalAlgorithmStatus,omitempty"`
+
+	// On-chain record; OnChain is omitted when the ledger wasn't consulted
+	OnChain         *bool    `json:"onChain,omitempty"`
+	SignerID        string   `json:"signerId,omitempty"` // keccak256(DID) as stored on-chain
+	ChainTimestamp  string   `json:"chainTimestamp,omitempty"`
+	ChainHardwareID string   `json:"chainHardwareId,omitempty"` // keccak256 of the hardware ID
+	ChainRevoked    bool     `json:"chainRevoked,omitempty"`
+	ChainAccount    string   `json:"chainAccount,omitempty"` // Server-held signer account that approved the anchor; empty if only the relayer vouches for it. Attribution, not non-repudiation
+	Mismatches      []string `json:"mismatches,omitempty"`   // Fields on which Postgres and the chain disagree
+
+	// Set once the signature is revoked
+	RevokedAt        string `json:"revokedAt,omitempty"`
+	RevocationReason string `json:"revocationReason,omitempty"`
+	RevocationTxHash string `json:"revocationTxHash,omitempty"`
+
+	// Inclusion proof when the signature was anchored as part of a Merkle batch
+	MerkleProof *MerkleProofInfo `json:"merkleProof,omitempty"`
+
+	// Redundant copies anchored on other networks
+	Copies []NetworkCopy `json:"copies,omitempty"`
+}
+
+// NetworkCopy is a signature's redundant anchor on a secondary network
+type NetworkCopy struct {
+	Network string `json:"network"`
+	Status  string `json:"status"` // Anchor job status: queued, processing, submitted, done, failed
+	TxHash  string `json:"txHash,omitempty"`
+
+	// Status of the copy's revocation job once the signature is revoked: a
+	// failed one means the copy is still live on that network
+	Revocation string `json:"revocation,omitempty"`
+}
+
+// MerkleProofInfo proves a signature is a leaf of an anchored batch root.
+// The leaf is keccak256(0x00 || docHash || signerId || payloadHash).
+type MerkleProofInfo struct {
+	Root        string             `json:"root"`
+	RootTxHash  string             `json:"rootTxHash,omitempty"`
+	Leaf        string             `json:"leaf"`
+	LeafIndex   int                `json:"leafIndex"`
+	Proof       []merkle.ProofStep `json:"proof"`
+	SignerID    string             `json:"signerId"`              // keccak256(DID)
+	PayloadHash string             `json:"payloadHash,omitempty"` // keccak256 of the anchored signature bytes
+	Valid       bool               `json:"valid"`                 // Proof recomputes the root from the leaf
 }
 
 type SignatureVerifyResponse struct {
-	IsValid     bool         `json:"isValid"`
-	Signer      string       `json:"signer,omitempty"`    // Kept for backwards compatibility (first signer)
-	Signers     []SignerInfo `json:"signers"`             // All signers for multi-party
-	SignerCount int          `json:"signerCount"`         // Total number of signers
-	Timestamp   string       `json:"timestamp,omitempty"` // First signature timestamp
-	LedgerTx    string       `json:"ledgerTx,omitempty"`  // First ledger tx
-	Status      string       `json:"status"`
+	IsValid     bool          `json:"isValid"`
+	Signer      string        `json:"signer,omitempty"`    // Kept for backwards compatibility (first signer)
+	Signers     []SignerInfo  `json:"signers"`             // All signers for multi-party
+	SignerCount int           `json:"signerCount"`         // Total number of signers
+	Timestamp   string        `json:"timestamp,omitempty"` // First signature timestamp
+	LedgerTx    string        `json:"ledgerTx,omitempty"`  // First ledger tx
+	Status      string        `json:"status"`              // First signer's status, or mismatch / unconfirmed
+	Ledger      string        `json:"ledger"`              // Ledger cross-checked: besu, simulated, or none
+	Network     string        `json:"network,omitempty"`   // Network cross-checked: the primary
+	Sources     VerifySources `json:"sources"`
 }
 
 // Verify handles GET /api/v1/verify/:docHash
+// Postgres is cross-checked against the contract whenever a ledger is
+// connected; ?requireChain=true (or VERIFY_REQUIRE_CHAIN) withholds a valid
+// result until the chain confirms every signer.
 func (h *SignatureHandler) Verify(c echo.Context) error {
 	docHash := c.Param("docHash")
 	if docHash == "" {
@@ -177,52 +483,162 @@ func (h *SignatureHandler) Verify(c echo.Context) error {
 
 	// Fetch ALL signatures for this document (multi-party support)
 	var signatures []models.SignatureMetadata
-	if err := db.DB.Preload("Signer").Where("doc_hash = ?", docHash).Order("created_at asc").Find(&signatures).Error; err != nil || len(signatures) == 0 {
+	if err := db.DB.Preload("Signer").Where("doc_hash = ?", docHash).Order("created_at asc").Find(&signatures).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to fetch signatures",
+		})
+	}
+
+	// Every signer the contract holds for this document, when a ledger is configured
+	chainRecords, chainChecked := fetchChainSigners(docHash, signatures)
+
+	if len(signatures) == 0 && len(chainRecords) == 0 {
+		sources := VerifySources{DB: SourceNotFound, Chain: SourceUnavailable}
+		if chainChecked {
+			sources.Chain = SourceNotFound
+		}
 		return c.JSON(http.StatusNotFound, SignatureVerifyResponse{
 			IsValid: false,
 			Status:  "not_found",
+			Ledger:  ledger.BackendName(),
+			Network: ledger.PrimaryNetwork(),
+			Sources: sources,
 		})
 	}
 
 	// Create audit log for the first signer
-	ipAddr := c.RealIP()
-	auditLog := models.AuditLog{
-		UserID:     signatures[0].SignerID,
-		ActionType: "signature_verify",
-		IPAddress:  &ipAddr,
-		Timestamp:  time.Now(),
+	if len(signatures) > 0 {
+		ipAddr := c.RealIP()
+		auditLog := models.AuditLog{
+			UserID:     signatures[0].SignerID,
+			ActionType: "signature_verify",
+			IPAddress:  &ipAddr,
+			Timestamp:  time.Now(),
+		}
+		db.DB.Create(&auditLog)
+	}
+
+	proofs := merkleProofs(signatures)
+	copies, err := redundantCopies(signatures)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to fetch signatures",
+		})
 	}
-	db.DB.Create(&auditLog)
 
-	// Build list of all signers
+	// Build list of all signers. Only anchored signatures count as valid: a
+	// revoked one, or one made with an algorithm that policy now forbids, is
+	// invalid, and one still queued or whose anchor failed is not valid yet.
+	invalid := false
+	pending, failed := 0, 0
 	signers := make([]SignerInfo, len(signatures))
 	for i, sig := range signatures {
 		txHash := ""
 		if sig.LedgerTxHash != nil {
 			txHash = *sig.LedgerTxHash
 		}
+		algStatus := signing.DefaultRegistry.Status(signing.Algorithm(sig.Algorithm))
+		if algStatus == signing.StatusForbidden {
+			invalid = true
+		}
+		var classicalStatus signing.Status
+		if sig.ClassicalAlgorithm != "" {
+			classicalStatus = signing.DefaultRegistry.Status(signing.Algorithm(sig.ClassicalAlgorithm))
+			if classicalStatus == signing.StatusForbidden {
+				invalid = true
+			}
+		}
 		signers[i] = SignerInfo{
-			DID:       sig.Signer.DIDAddress,
-			Timestamp: sig.CreatedAt.Format(time.RFC3339),
-			TxHash:    txHash,
+			DID:                      sig.Signer.DIDAddress,
+			Account:                  sig.Signer.ChainAddress,
+			Timestamp:                sig.CreatedAt.Format(time.RFC3339),
+			TxHash:                   txHash,
+			LedgerBackend:            sig.LedgerBackend,
+			LedgerNetwork:            sig.LedgerNetwork,
+			Status:                   sig.Status,
+			Confirmations:            sig.Confirmations,
+			Algorithm:                sig.Algorithm,
+			KeyID:                    sig.KeyID,
+			AlgorithmStatus:          string(algStatus),
+			PQCSignature:             sig.PQCSignature,
+			ClassicalAlgorithm:       sig.ClassicalAlgorithm,
+			ClassicalKeyID:           sig.ClassicalKeyID,
+			ClassicalSignature:       sig.ClassicalSignature,
+			ClassicalAlgorithmStatus: string(classicalStatus),
+		}
+		if sig.BlockNumber != nil {
+			signers[i].BlockNumber = *sig.BlockNumber
+		}
+		if sig.BlockTimestamp != nil {
+			signers[i].BlockTimestamp = sig.BlockTimestamp.Format(time.RFC3339)
+		}
+		signers[i].MerkleProof = proofs[sig.ID]
+		signers[i].Copies = copies[sig.ID]
+		switch sig.Status {
+		case anchoring.SignatureAnchored, "verified":
+		case anchoring.SignatureRevoked:
+			invalid = true
+		case anchoring.SignatureFailed:
+			failed++
+		default:
+			pending++
+		}
+		if sig.RevokedAt != nil {
+			signers[i].RevokedAt = sig.RevokedAt.Format(time.RFC3339)
+			signers[i].RevocationReason = sig.RevocationReason
+			if sig.RevocationTxHash != nil {
+				signers[i].RevocationTxHash = *sig.RevocationTxHash
+			}
 		}
 	}
 
-	// Get first signature for backwards compatibility
-	firstSig := signatures[0]
-	ledgerTx := ""
-	if firstSig.LedgerTxHash != nil {
-		ledgerTx = *firstSig.LedgerTxHash
+	isValid := !invalid && failed == 0 && pending == 0
+	sources := VerifySources{DB: SourceValid, Chain: SourceUnavailable}
+	switch {
+	case len(signatures) == 0:
+		sources.DB = SourceNotFound
+	case invalid:
+		sources.DB = SourceInvalid
+	case failed > 0:
+		sources.DB = SourceFailed
+	case pending > 0:
+		sources.DB = SourcePending
+	}
+	if chainChecked {
+		signers = crossCheck(signers, signatures, chainRecords)
+		sources.Chain = chainSource(signers)
+		for _, s := range signers {
+			if s.ChainRevoked {
+				isValid = false
+			}
+		}
+	}
+
+	// Get first signer for backwards compatibility
+	first := signers[0]
+	status := first.Status
+	switch {
+	case sources.Chain == SourceMismatch:
+		isValid = false
+		status = SourceMismatch
+	case sources.DB == SourceNotFound:
+		isValid = false
+	case (h.requireChain || c.QueryParam("requireChain") == "true") && sources.Chain != SourceValid:
+		isValid = false
+		status = "unconfirmed"
 	}
 
 	return c.JSON(http.StatusOK, SignatureVerifyResponse{
-		IsValid:     true,
-		Signer:      firstSig.Signer.DIDAddress,
+		IsValid:     isValid,
+		Signer:      first.DID,
 		Signers:     signers,
 		SignerCount: len(signers),
-		Timestamp:   firstSig.CreatedAt.Format(time.RFC3339),
-		LedgerTx:    ledgerTx,
-		Status:      firstSig.Status,
+		Timestamp:   first.Timestamp,
+		LedgerTx:    first.TxHash,
+		Status:      status,
+		Ledger:      ledger.BackendName(),
+		Network:     ledger.PrimaryNetwork(),
+		Sources:     sources,
 	})
 }
 
@@ -242,9 +658,11 @@ type RecentSignatureResponse struct {
 
 // GetRecent handles GET /api/v1/signatures/recent
 func (h *SignatureHandler) GetRecent(c echo.Context) error {
+	user := auth.CurrentUser(c)
+
 	var signatures []models.SignatureMetadata
-	// Fetch last 10 signatures, preload Signer for DID
-	if err := db.DB.Preload("Signer").Order("created_at desc").Limit(10).Find(&signatures).Error; err != nil {
+	// Fetch the caller's last 10 signatures, preload Signer for DID
+	if err := db.DB.Preload("Signer").Where("signer_id = ?", user.ID).Order("created_at desc").Limit(10).Find(&signatures).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to fetch signatures",
 		})
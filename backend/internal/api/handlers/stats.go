@@ -3,10 +3,13 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/inkless/backend/internal/auth"
 	"github.com/inkless/backend/internal/db"
 	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
 	"github.com/labstack/echo/v4"
 )
 
@@ -30,12 +33,7 @@ type StatMetric struct {
 
 // GetDashboardStats calculates real-time stats for the user
 func (h *StatsHandler) GetDashboardStats(c echo.Context) error {
-	// Demo user
-	var user models.User
-	if err := db.DB.Where("did_address = ?", "did:inkless:demo").First(&user).Error; err != nil {
-		// If user doesn't exist yet, return defaults
-		return c.JSON(http.StatusOK, getDefaultStats())
-	}
+	user := auth.CurrentUser(c)
 
 	// 1. Calculate Signature Velocity
 	var currentMonthCount int64
@@ -97,7 +95,23 @@ func (h *StatsHandler) GetDashboardStats(c echo.Context) error {
 		securityMsg = "At Risk"
 	}
 
-	// 3. Network Status (Mock/Hardcoded for now as it's global)
+	// 3. Network Status, as last checked by the ledger supervisor: the
+	// worst of the configured networks
+	networks := ledger.NetworkHealth()
+	if len(networks) == 0 {
+		networks = []ledger.Health{ledger.CurrentHealth()}
+	}
+	labels := make([]string, len(networks))
+	networkMsg, networkTrend := "Operational", "up"
+	for i, network := range networks {
+		labels[i] = network.Network
+		switch {
+		case network.Status == ledger.HealthDown:
+			networkMsg, networkTrend = "Down", "down"
+		case network.Status == ledger.HealthDegraded && networkTrend == "up":
+			networkMsg, networkTrend = "Degraded", "neutral"
+		}
+	}
 
 	return c.JSON(http.StatusOK, DashboardStatsResponse{
 		Velocity: StatMetric{
@@ -111,17 +125,9 @@ func (h *StatsHandler) GetDashboardStats(c echo.Context) error {
 			Trend:  "up",
 		},
 		NetworkStatus: StatMetric{
-			Value:  "Polygon PoS",
-			Change: "Operational",
-			Trend:  "up",
+			Value:  strings.Join(labels, " + "),
+			Change: networkMsg,
+			Trend:  networkTrend,
 		},
 	})
 }
-
-func getDefaultStats() DashboardStatsResponse {
-	return DashboardStatsResponse{
-		Velocity:      StatMetric{Value: "0", Change: "0%", Trend: "neutral"},
-		SecurityScore: StatMetric{Value: "50/100", Change: "Setup Required", Trend: "neutral"},
-		NetworkStatus: StatMetric{Value: "Polygon PoS", Change: "Operational", Trend: "up"},
-	}
-}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/inkless/backend/internal/db/models"
+)
+
+// admins holds the DIDs allowed to act on other users' records
+var (
+	adminsMu sync.RWMutex
+	admins   = map[string]bool{}
+)
+
+// SetAdmins replaces the admin DID list (ADMIN_DIDS)
+func SetAdmins(dids []string) {
+	set := make(map[string]bool, len(dids))
+	for _, did := range dids {
+		set[did] = true
+	}
+
+	adminsMu.Lock()
+	admins = set
+	adminsMu.Unlock()
+}
+
+// IsAdmin reports whether user is an administrator
+func IsAdmin(user *models.User) bool {
+	if user == nil {
+		return false
+	}
+	adminsMu.RLock()
+	defer adminsMu.RUnlock()
+	return admins[user.DIDAddress]
+}
+
+// RequireAdmin rejects callers that aren't administrators with 403. It runs
+// after RequireUser.
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !IsAdmin(CurrentUser(c)) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Administrator access required",
+			})
+		}
+		return next(c)
+	}
+}
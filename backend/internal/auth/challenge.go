@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/db/models"
+)
+
+// ChallengeTTL is how long a device has to sign a login challenge
+const ChallengeTTL = 2 * time.Minute
+
+// challengeDomain prefixes the signed message so a login signature can never
+// be replayed as (or confused with) a document signature
+const challengeDomain = "inkless-auth-challenge:v1:"
+
+// ErrChallengeInvalid is returned for unknown, expired or already-used challenges
+var ErrChallengeInvalid = errors.New("invalid or expired challenge")
+
+// NewChallenge stores a fresh random nonce for a DID. Challenges are issued
+// whether or not the DID exists so the endpoint can't be used to enumerate users.
+func NewChallenge(did string) (*models.AuthChallenge, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	challenge := models.AuthChallenge{
+		DIDAddress: did,
+		Nonce:      hex.EncodeToString(buf),
+		ExpiresAt:  time.Now().Add(ChallengeTTL),
+	}
+	if err := db.DB.Create(&challenge).Error; err != nil {
+		return nil, fmt.Errorf("failed to store challenge: %w", err)
+	}
+
+	return &challenge, nil
+}
+
+// ConsumeChallenge marks a challenge used and returns it. It is burned before
+// the signature is checked, so every challenge allows exactly one attempt.
+func ConsumeChallenge(id uuid.UUID) (*models.AuthChallenge, error) {
+	now := time.Now()
+	result := db.DB.Model(&models.AuthChallenge{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrChallengeInvalid
+	}
+
+	var challenge models.AuthChallenge
+	if err := db.DB.First(&challenge, id).Error; err != nil {
+		return nil, ErrChallengeInvalid
+	}
+	return &challenge, nil
+}
+
+// ChallengeMessage is the exact byte string the device must sign
+func ChallengeMessage(challenge *models.AuthChallenge) []byte {
+	return []byte(challengeDomain + challenge.ID.String() + ":" + challenge.Nonce)
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/db/models"
+)
+
+// Context keys set by RequireUser
+const (
+	userContextKey   = "inkless.user"
+	claimsContextKey = "inkless.claims"
+)
+
+// RequireUser returns middleware that validates the bearer access token,
+// loads the matching models.User by DID and stores it in the echo.Context.
+// Requests without a valid token are rejected with 401.
+func RequireUser(secret string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tokenString, ok := bearerToken(c.Request())
+			if !ok {
+				return unauthorized(c, "Missing access token")
+			}
+
+			claims, err := ParseAccessToken(secret, tokenString)
+			if err != nil {
+				return unauthorized(c, "Invalid or expired access token")
+			}
+
+			var user models.User
+			if err := db.DB.Where("d_id_address = ?", claims.Subject).First(&user).Error; err != nil {
+				return unauthorized(c, "Unknown user")
+			}
+			if !IsActive(&user) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": InactiveMessage(&user),
+				})
+			}
+
+			// Tokens die with their device: RemoveDevice / RevokeAllDevices take effect immediately
+			if claims.DeviceID != "" {
+				var active int64
+				db.DB.Model(&models.TrustedDevice{}).
+					Where("id = ? AND user_id = ? AND is_active = ?", claims.DeviceID, user.ID, true).
+					Count(&active)
+				if active == 0 {
+					return unauthorized(c, "Device has been signed out")
+				}
+			}
+
+			c.Set(userContextKey, &user)
+			c.Set(claimsContextKey, claims)
+			return next(c)
+		}
+	}
+}
+
+// CurrentUser returns the authenticated user stored by RequireUser.
+// It returns nil on routes that are not wrapped by the middleware.
+func CurrentUser(c echo.Context) *models.User {
+	user, _ := c.Get(userContextKey).(*models.User)
+	return user
+}
+
+// CurrentClaims returns the validated token claims stored by RequireUser
+func CurrentClaims(c echo.Context) *Claims {
+	claims, _ := c.Get(claimsContextKey).(*Claims)
+	return claims
+}
+
+// CurrentDeviceID returns the TrustedDevice the access token was issued to,
+// or uuid.Nil if the token is not bound to a device
+func CurrentDeviceID(c echo.Context) uuid.UUID {
+	claims := CurrentClaims(c)
+	if claims == nil {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(claims.DeviceID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get(echo.HeaderAuthorization)
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="inkless"`)
+	return c.JSON(http.StatusUnauthorized, map[string]string{
+		"error": message,
+	})
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/db/models"
+)
+
+var (
+	// ErrRefreshTokenInvalid is returned for unknown, expired or revoked refresh tokens
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already-rotated token is presented again.
+	// The whole token family is revoked before this is returned.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// IssueRefreshToken creates a refresh token for a device. Pass uuid.Nil as
+// familyID to start a new family (i.e. a fresh login).
+func IssueRefreshToken(tx *gorm.DB, userID, deviceID, familyID uuid.UUID) (string, time.Time, error) {
+	raw, err := newOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if familyID == uuid.Nil {
+		familyID = uuid.New()
+	}
+
+	record := models.RefreshToken{
+		UserID:    userID,
+		DeviceID:  deviceID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return raw, record.ExpiresAt, nil
+}
+
+// RotateRefreshToken spends a refresh token and issues its successor in the
+// same family. It returns the spent record so callers can reissue an access
+// token for the same user and device. On ErrRefreshTokenReused the spent
+// record is still returned so the reuse can be attributed.
+func RotateRefreshToken(raw string) (*models.RefreshToken, string, time.Time, error) {
+	var (
+		current   models.RefreshToken
+		next      string
+		expiresAt time.Time
+		reused    bool
+	)
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Device").Where("token_hash = ?", hashToken(raw)).First(&current).Error; err != nil {
+			return ErrRefreshTokenInvalid
+		}
+
+		if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) || !current.Device.IsActive {
+			return ErrRefreshTokenInvalid
+		}
+
+		// Conditional update so two concurrent refreshes can't both win
+		now := time.Now()
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", current.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return ErrRefreshTokenReused
+		}
+
+		var err error
+		next, expiresAt, err = IssueRefreshToken(tx, current.UserID, current.DeviceID, current.FamilyID)
+		return err
+	})
+
+	if reused {
+		// Revoke outside the rolled-back transaction so it sticks
+		if err := RevokeFamily(current.FamilyID); err != nil {
+			return nil, "", time.Time{}, err
+		}
+		return &current, "", time.Time{}, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+
+	return &current, next, expiresAt, nil
+}
+
+// RevokeFamily revokes every refresh token in a family
+func RevokeFamily(familyID uuid.UUID) error {
+	return db.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeDeviceTokens revokes every refresh token issued to the given devices.
+// Access tokens for those devices are rejected by RequireUser once the device
+// is inactive, so this completes the sign-out.
+func RevokeDeviceTokens(deviceIDs ...uuid.UUID) error {
+	if len(deviceIDs) == 0 {
+		return nil
+	}
+	return db.DB.Model(&models.RefreshToken{}).
+		Where("device_id IN ? AND revoked_at IS NULL", deviceIDs).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserTokens revokes every refresh token issued to a user
+func RevokeUserTokens(userID uuid.UUID) error {
+	return db.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
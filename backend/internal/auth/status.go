@@ -0,0 +1,24 @@
+package auth
+
+import "github.com/inkless/backend/internal/db/models"
+
+// User account states
+const (
+	UserActive    = "active"
+	UserSuspended = "suspended" // Blocked by an administrator; may be reinstated
+	UserDeleted   = "deleted"   // Closed, with personal data cleared
+)
+
+// IsActive reports whether user may sign in and act. Rows created before
+// account states existed have none and are active.
+func IsActive(user *models.User) bool {
+	return user.Status == "" || user.Status == UserActive
+}
+
+// InactiveMessage is the error shown to a user who isn't active
+func InactiveMessage(user *models.User) string {
+	if user.Status == UserDeleted {
+		return "Account has been deleted"
+	}
+	return "Account is suspended"
+}
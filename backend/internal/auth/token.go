@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/inkless/backend/internal/db"
+	"github.com/inkless/backend/internal/db/models"
+)
+
+// Issuer is the "iss" claim on every token minted by the Inkless API
+const Issuer = "inkless-api"
+
+// Token lifetimes
+const (
+	AccessTokenTTL       = 15 * time.Minute
+	RefreshTokenTTL      = 30 * 24 * time.Hour
+	VerificationGrantTTL = 5 * time.Minute
+)
+
+// Token uses, carried in the "use" claim so one kind can't stand in for another
+const (
+	tokenUseAccess       = "access"
+	tokenUseVerification = "verification"
+)
+
+// ErrInvalidToken is returned when a token is malformed, expired or badly signed
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrGrantUsed is returned for a verification grant that was already exchanged
+var ErrGrantUsed = errors.New("verification grant already used")
+
+// Claims are the JWT claims carried by Inkless tokens.
+// The subject is the user's DID, which is how the middleware resolves the caller.
+type Claims struct {
+	Use      string `json:"use"`
+	DeviceID string `json:"dev,omitempty"` // TrustedDevice the token was issued to
+	jwt.RegisteredClaims
+}
+
+// IssueAccessToken mints a short-lived access token for a user on a trusted device
+func IssueAccessToken(secret, did string, deviceID uuid.UUID) (string, time.Time, error) {
+	return issueToken(secret, did, tokenUseAccess, deviceID.String(), uuid.New().String(), AccessTokenTTL)
+}
+
+// IssueVerificationGrant mints the short-lived grant returned by identity
+// verification. It can only be exchanged for tokens at POST /auth/token.
+// Pass the TrustedDevice created by device linking, or uuid.Nil for a new one.
+// The grant is recorded so ConsumeVerificationGrant lets it through once.
+func IssueVerificationGrant(secret, did string, deviceID uuid.UUID) (string, time.Time, error) {
+	dev := ""
+	if deviceID != uuid.Nil {
+		dev = deviceID.String()
+	}
+	grant := models.VerificationGrant{
+		ID:         uuid.New(),
+		DIDAddress: did,
+		ExpiresAt:  time.Now().Add(VerificationGrantTTL),
+	}
+	if err := db.DB.Create(&grant).Error; err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store verification grant: %w", err)
+	}
+	return issueToken(secret, did, tokenUseVerification, dev, grant.ID.String(), VerificationGrantTTL)
+}
+
+// ConsumeVerificationGrant marks the grant claims were parsed from used.
+// Call it in the transaction that acts on the grant, so a failure releases
+// it and a second exchange finds it used.
+func ConsumeVerificationGrant(tx *gorm.DB, claims *Claims) error {
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return ErrGrantUsed
+	}
+	now := time.Now()
+	result := tx.Model(&models.VerificationGrant{}).
+		Where("id = ? AND did_address = ? AND used_at IS NULL AND expires_at > ?", id, claims.Subject, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrGrantUsed
+	}
+	return nil
+}
+
+// ParseAccessToken validates an HS256-signed access token and returns its claims
+func ParseAccessToken(secret, tokenString string) (*Claims, error) {
+	return parseToken(secret, tokenString, tokenUseAccess)
+}
+
+// ParseVerificationGrant validates a grant issued by IssueVerificationGrant
+func ParseVerificationGrant(secret, tokenString string) (*Claims, error) {
+	return parseToken(secret, tokenString, tokenUseVerification)
+}
+
+func issueToken(secret, subject, use, deviceID, id string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims := Claims{
+		Use:      use,
+		DeviceID: deviceID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        id,
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+func parseToken(secret, tokenString, use string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid || claims.Subject == "" || claims.Use != use {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
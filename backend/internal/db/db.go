@@ -5,6 +5,7 @@ import (
 
 	"github.com/inkless/backend/internal/config"
 	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -48,12 +49,45 @@ func Migrate() error {
 		&models.OfflineSignature{},
 		&models.TrustedDevice{},
 		&models.UserPreferences{},
+		&models.RefreshToken{},
+		&models.AuthChallenge{},
+		&models.VerificationGrant{},
+		&models.DeviceLinkRequest{},
+		&models.AnchorJob{},
+		&models.LedgerCheckpoint{},
+		&models.LedgerEvent{},
+		&models.LedgerAnchor{},
+		&models.LedgerSigner{},
+		&models.LedgerDrift{},
 	)
 
 	if err != nil {
 		return err
 	}
 
+	// A signature may now have one job per network
+	if DB.Migrator().HasIndex(&models.AnchorJob{}, "idx_anchor_jobs_signature_id") {
+		if err := DB.Migrator().DropIndex(&models.AnchorJob{}, "idx_anchor_jobs_signature_id"); err != nil {
+			return err
+		}
+	}
+
+	// A signature's revocation is a job of its own on the same network
+	if DB.Migrator().HasIndex(&models.AnchorJob{}, "idx_anchor_job_signature_network") {
+		if err := DB.Migrator().DropIndex(&models.AnchorJob{}, "idx_anchor_job_signature_network"); err != nil {
+			return err
+		}
+	}
+
+	// Label rows anchored before ledger_backend existed: mock mode's
+	// fabricated hashes are 34 characters, a node's 66
+	if err := DB.Exec(`UPDATE signature_metadata
+		SET ledger_backend = CASE WHEN length(ledger_tx_hash) = 66 THEN ? ELSE ? END
+		WHERE (ledger_backend IS NULL OR ledger_backend = '') AND ledger_tx_hash IS NOT NULL`,
+		ledger.BackendBesu, ledger.BackendMock).Error; err != nil {
+		return err
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
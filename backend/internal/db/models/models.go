@@ -9,14 +9,19 @@ import (
 
 // User represents a registered user with their DID and device info
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	DIDAddress   string    `gorm:"uniqueIndex;not null"`
-	VNINHash     *string   `gorm:"index"` // Nullable until NIMC verification
-	DevicePubKey string    `gorm:"not null"`
-	FullName     string    `gorm:"type:varchar(255)"`
-	Email        string    `gorm:"type:varchar(255)"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	DIDAddress      string    `gorm:"uniqueIndex;not null"`
+	VNINHash        *string   `gorm:"uniqueIndex"` // Peppered HMAC of the vNIN; nullable until NIMC verification. Kept on rows an administrator deleted, so that vNIN is refused
+	DevicePubKey    string    `gorm:"not null"`
+	ClassicalPubKey string    // Optional ECDSA/Ed25519 key for hybrid signatures
+	ChainAddress    string    `gorm:"type:varchar(42);index"` // Signer account derived for the DID; approves its anchors on-chain with a server-held key
+	FullName        string    `gorm:"type:varchar(255)"`
+	Email           string    `gorm:"type:varchar(255)"`
+	Status          string    `gorm:"type:varchar(16);not null;default:active;index"` // active, suspended or deleted
+	StatusReason    string    `gorm:"type:text"`
+	StatusChangedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 
 	// Relationships
 	AuditLogs  []AuditLog          `gorm:"foreignKey:UserID"`
@@ -38,18 +43,42 @@ type AuditLog struct {
 
 // SignatureMetadata stores document signature metadata (NOT the document itself)
 type SignatureMetadata struct {
-	ID               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	DocHash          string    `gorm:"index;not null;uniqueIndex:idx_doc_signer"`           // SHA-3 hash of the document (composite key with SignerID)
-	SignerID         uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_doc_signer"` // Composite unique with DocHash
-	DocumentCategory string    `gorm:"not null;default:'general_contract'"`                 // Added for legal compliance
-	FileName         string    `gorm:"type:varchar(255)"`                                   // Original filename
-	FileSize         string    `gorm:"type:varchar(50)"`                                    // Human readable size
-	MimeType         string    `gorm:"type:varchar(100)"`                                   // e.g. application/pdf
-	LedgerTxHash     *string   `gorm:"index"`                                               // Blockchain transaction hash
-	Status           string    `gorm:"default:pending"`                                     // pending, anchored, verified, revoked
-	HardwareID       string    `gorm:"not null"`                                            // Hash of device TPM/Secure Enclave ID
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	DocHash            string    `gorm:"index;not null;uniqueIndex:idx_doc_signer"`           // SHA-3 hash of the document (composite key with SignerID)
+	SignerID           uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_doc_signer"` // Composite unique with DocHash
+	DocumentCategory   string    `gorm:"not null;default:'general_contract'"`                 // Added for legal compliance
+	FileName           string    `gorm:"type:varchar(255)"`                                   // Original filename
+	FileSize           string    `gorm:"type:varchar(50)"`                                    // Human readable size
+	MimeType           string    `gorm:"type:varchar(100)"`                                   // e.g. application/pdf
+	LedgerTxHash       *string   `gorm:"index"`                                               // Blockchain transaction hash
+	LedgerBackend      string    `gorm:"type:varchar(16)"`                                    // Ledger that anchored it: besu, simulated, or mock for legacy fabricated hashes
+	LedgerNetwork      string    `gorm:"type:varchar(32)"`                                    // Configured network the row is anchored on; empty for rows predating named networks (the primary)
+	Status             string    `gorm:"default:pending"`                                     // pending, submitted, anchored, failed, verified, revoked
+	HardwareID         string    `gorm:"not null"`                                            // Hash of device TPM/Secure Enclave ID
+	Algorithm          string    `gorm:"type:varchar(32)"`                                    // Signature algorithm ID, e.g. ml-dsa-65 (empty for legacy rows)
+	KeyID              string    `gorm:"type:varchar(64);index"`                              // Fingerprint of the public key that verified the signature
+	PQCSignature       []byte    `gorm:"type:bytea"`                                          // Post-quantum signature as verified
+	ClassicalAlgorithm string    `gorm:"type:varchar(32)"`                                    // Set for hybrid signatures, e.g. ecdsa-p256
+	ClassicalKeyID     string    `gorm:"type:varchar(64)"`                                    // Fingerprint of the classical key
+	ClassicalSignature []byte    `gorm:"type:bytea"`                                          // Classical part of a hybrid signature
+	MerkleRoot         string    `gorm:"type:varchar(66);index"`                              // Set when anchored as part of a batch
+	MerkleLeaf         string    `gorm:"type:varchar(66)"`
+	MerkleIndex        *int
+	MerkleProof        *string `gorm:"type:jsonb"`       // Sibling path from MerkleLeaf to MerkleRoot
+	BlockNumber        *uint64 `gorm:"index"`            // Block that included the anchor tx
+	BlockHash          string  `gorm:"type:varchar(66)"` // Used to notice the block being reorged away
+	BlockTimestamp     *time.Time
+	GasUsed            *uint64
+	Confirmations      int `gorm:"not null;default:0"` // Depth of BlockNumber below the head when last checked
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+
+	// Set by the revoke API
+	RevokedAt        *time.Time
+	RevokedBy        *uuid.UUID `gorm:"type:uuid"`        // Signer, or the admin who revoked it
+	RevocationReason string     `gorm:"type:varchar(32)"` // e.g. ndpa_erasure, key_compromise
+	RevocationNote   string     `gorm:"type:varchar(500)"`
+	RevocationTxHash *string    // Ledger tx that revoked the on-chain record
 
 	// Relationships
 	Signer User `gorm:"foreignKey:SignerID"`
@@ -68,12 +97,14 @@ type VerificationToken struct {
 
 // OfflineSignature stores signatures made offline, pending sync
 type OfflineSignature struct {
-	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	DocHash      string    `gorm:"not null"`
-	PQCSignature []byte    `gorm:"type:bytea;not null"` // Post-quantum signature bytes
-	HardwareID   string    `gorm:"not null"`
-	LocalTS      time.Time `gorm:"not null"`        // Timestamp when signed offline
-	SyncStatus   string    `gorm:"default:pending"` // pending, synced, failed
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID       *uuid.UUID `gorm:"type:uuid;index"` // Signer who synced it (nullable for legacy rows)
+	DocHash      string     `gorm:"not null"`
+	PQCSignature []byte     `gorm:"type:bytea;not null"` // Post-quantum signature bytes
+	Algorithm    string     `gorm:"type:varchar(32)"`    // Signature algorithm ID
+	HardwareID   string     `gorm:"not null"`
+	LocalTS      time.Time  `gorm:"not null"`        // Timestamp when signed offline
+	SyncStatus   string     `gorm:"default:pending"` // pending, synced, failed
 	ErrorMessage *string
 	CreatedAt    time.Time
 	SyncedAt     *time.Time
@@ -88,6 +119,7 @@ type TrustedDevice struct {
 	UserAgent  string    `gorm:"type:text"`         // Full user agent string
 	IPAddress  string    `gorm:"type:varchar(45)"`  // IPv4 or IPv6
 	Location   string    `gorm:"type:varchar(100)"` // Derived from IP, e.g., "Lagos, NG"
+	PublicKey  string    `gorm:"type:text"`         // Device key added through device linking (primary key lives on User)
 	LastSeenAt time.Time
 	IsActive   bool `gorm:"default:true"`
 	CreatedAt  time.Time
@@ -97,6 +129,160 @@ type TrustedDevice struct {
 	User User `gorm:"foreignKey:UserID"`
 }
 
+// DeviceLinkRequest is raised when an already-registered vNIN is verified
+// from a device with a different key. An existing trusted device must approve
+// it before the new device gets credentials, so re-verification never forks
+// the identity or silently replaces its key.
+type DeviceLinkRequest struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index"`
+	DevicePubKey string     `gorm:"type:text;not null"`
+	DeviceName   string     `gorm:"type:varchar(255)"`
+	IPAddress    string     `gorm:"type:varchar(45)"`
+	Status       string     `gorm:"not null;default:pending"` // pending, approved, rejected, claimed
+	DeviceID     *uuid.UUID `gorm:"type:uuid"`                // TrustedDevice created on approval
+	ApprovedBy   *uuid.UUID `gorm:"type:uuid"`                // TrustedDevice that approved it
+	ExpiresAt    time.Time  `gorm:"not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// RefreshToken is a rotating refresh credential bound to a TrustedDevice.
+// Only the SHA-256 of the opaque token is stored. Every rotation stays in the
+// same FamilyID so that reuse of a spent token can revoke the whole chain.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	DeviceID  uuid.UUID  `gorm:"type:uuid;not null;index"` // TrustedDevice that requested the token
+	FamilyID  uuid.UUID  `gorm:"type:uuid;not null;index"` // Shared by every rotation of one login
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // Set when rotated; presenting it again is a reuse
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	// Relationships
+	User   User          `gorm:"foreignKey:UserID"`
+	Device TrustedDevice `gorm:"foreignKey:DeviceID"`
+}
+
+// AuthChallenge is a one-time nonce a device signs with its registered key to log in
+type AuthChallenge struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	DIDAddress string     `gorm:"index;not null"` // DID the challenge was requested for
+	Nonce      string     `gorm:"type:varchar(64);not null"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	UsedAt     *time.Time // Set on the first response attempt; challenges are never reused
+	CreatedAt  time.Time
+}
+
+// VerificationGrant records a grant minted by identity verification, keyed
+// by its jti, so it can be exchanged for tokens only once
+type VerificationGrant struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key"` // The grant's jti
+	DIDAddress string     `gorm:"index;not null"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	UsedAt     *time.Time // Set when exchanged at POST /auth/token
+	CreatedAt  time.Time
+}
+
+// AnchorJob is a durable ledger submission for one SignatureMetadata row,
+// a batch of them, or a change to a user's verified signer status. Workers claim due jobs with SELECT ... FOR UPDATE SKIP LOCKED, so any number
+// of API instances can share the table.
+type AnchorJob struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	SignatureID   *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_anchor_job_signature_network_kind"`                  // Nil for batch and signer jobs
+	Kind          string     `gorm:"not null;default:signature;uniqueIndex:idx_anchor_job_signature_network_kind"` // signature, batch for a Merkle root, add_signer / remove_signer, or revoke
+	BatchJobID    *uuid.UUID `gorm:"type:uuid;index"`                                                              // Batch job that anchors this signature's leaf
+	DocHash       string     `gorm:"not null"`                                                                     // Merkle root for batch jobs; the account for signer changes
+	SignerDID     string     // Each (docHash, signer) pair is anchored separately; empty for batch jobs, the account's owner for signer changes
+	Payload       []byte     `gorm:"type:bytea;not null"` // Bytes anchored on-chain (PQC or composite signature, or batch descriptor)
+	HardwareID    string     `gorm:"not null"`
+	Status        string     `gorm:"not null;default:queued;index"` // queued, processing, submitted, done, failed, batched
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"not null;index"`
+	LockedAt      *time.Time // Set while a worker holds the job
+	LastError     *string    `gorm:"type:text"`
+	TxHash        *string    `gorm:"index"`            // Latest submission, watched until confirmed
+	PriorTxHashes string     `gorm:"type:text"`        // Comma-separated hashes replaced by gas bumps; any of them may still be mined
+	BlockHash     string     `gorm:"type:varchar(66)"` // Block the tx was last seen in, to notice reorgs
+	SubmittedAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	// Named networks: a signature gets one job per network it is routed to
+	Network   string `gorm:"type:varchar(32);not null;default:'';uniqueIndex:idx_anchor_job_signature_network_kind"` // Empty for jobs predating named networks (the primary)
+	Redundant bool   `gorm:"not null;default:false"`                                                                 // A copy on a secondary network; it never changes the signature row
+
+	// Relationships
+	Signature *SignatureMetadata `gorm:"foreignKey:SignatureID"`
+}
+
+// LedgerCheckpoint records the last block the event indexer has processed for a contract
+type LedgerCheckpoint struct {
+	Contract    string `gorm:"type:varchar(42);primary_key"`
+	BlockNumber uint64 `gorm:"not null"`
+	BlockHash   string `gorm:"type:varchar(66);not null"` // Compared on the next pass to notice a reorg
+	UpdatedAt   time.Time
+}
+
+// LedgerEvent is one InklessRegistry log as indexed from the chain
+type LedgerEvent struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Contract       string    `gorm:"type:varchar(42);not null;default:'';index"` // Address that emitted the log, as LedgerCheckpoint keys it
+	BlockNumber    uint64    `gorm:"not null;index"`
+	BlockHash      string    `gorm:"type:varchar(66);not null"`
+	TxHash         string    `gorm:"type:varchar(66);not null;uniqueIndex:idx_ledger_event_log"`
+	LogIndex       uint      `gorm:"not null;uniqueIndex:idx_ledger_event_log"`
+	Event          string    `gorm:"type:varchar(32);not null"` // SignatureAnchored, SignerAnchored, SignatureRevoked, SignerRevoked, SignerVerified, SignerRemoved
+	DocHash        string    `gorm:"type:varchar(66);index"`
+	SignerID       string    `gorm:"type:varchar(66)"` // keccak256(DID), per-signer events only
+	Account        string    `gorm:"type:varchar(42);index"`
+	HardwareID     string    `gorm:"type:varchar(66)"`
+	ChainTimestamp time.Time
+	CreatedAt      time.Time
+}
+
+// LedgerAnchor mirrors one on-chain signature record, rebuilt from LedgerEvents
+type LedgerAnchor struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	DocHash      string     `gorm:"type:varchar(66);not null;uniqueIndex:idx_ledger_anchor"`
+	SignerID     string     `gorm:"type:varchar(66);not null;default:'';uniqueIndex:idx_ledger_anchor"` // Empty for document-keyed records (pre-multi-signer anchors, batch roots)
+	Submitter    string     `gorm:"type:varchar(42)"`
+	HardwareID   string     `gorm:"type:varchar(66)"`
+	AnchoredAt   *time.Time // Chain timestamp; nil if only the revocation was indexed
+	BlockNumber  uint64
+	TxHash       string `gorm:"type:varchar(66);index"`
+	Revoked      bool   `gorm:"not null;default:false"`
+	RevokedAt    *time.Time
+	RevokeTxHash *string
+	SignatureID  *uuid.UUID `gorm:"type:uuid;index"` // Matching Postgres signature; nil for batch roots and unmatched anchors
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// LedgerSigner mirrors the contract's verifiedSigners mapping
+type LedgerSigner struct {
+	Address     string `gorm:"type:varchar(42);primary_key"`
+	Verified    bool   `gorm:"not null"`
+	BlockNumber uint64
+	UpdatedAt   time.Time
+}
+
+// LedgerDrift flags a disagreement between Postgres and the chain found by the indexer
+type LedgerDrift struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Kind           string     `gorm:"type:varchar(32);not null;index"` // missing_on_chain, missing_in_db
+	DocHash        string     `gorm:"type:varchar(66);index"`
+	SignatureID    *uuid.UUID `gorm:"type:uuid;index"` // missing_on_chain
+	LedgerAnchorID *uuid.UUID `gorm:"type:uuid;index"` // missing_in_db
+	DetectedAt     time.Time  `gorm:"not null"`
+	ResolvedAt     *time.Time `gorm:"index"` // Set once the sources agree again
+}
+
 // UserPreferences stores user settings like theme and notifications
 type UserPreferences struct {
 	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
@@ -163,6 +349,65 @@ func (d *TrustedDevice) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeCreate hook for DeviceLinkRequest
+func (d *DeviceLinkRequest) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate hook for RefreshToken
+func (r *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate hook for AuthChallenge
+func (a *AuthChallenge) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate hook for AnchorJob
+func (j *AnchorJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	if j.NextAttemptAt.IsZero() {
+		j.NextAttemptAt = time.Now()
+	}
+	return nil
+}
+
+// BeforeCreate hook for LedgerEvent
+func (e *LedgerEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate hook for LedgerAnchor
+func (a *LedgerAnchor) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate hook for LedgerDrift
+func (d *LedgerDrift) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
 // BeforeCreate hook for UserPreferences
 func (p *UserPreferences) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == uuid.Nil {
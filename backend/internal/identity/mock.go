@@ -0,0 +1,34 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MockProvider accepts any non-empty vNIN and consent token. For development only.
+type MockProvider struct {
+	pepper string
+}
+
+// NewMockProvider creates a new MockProvider
+func NewMockProvider(pepper string) *MockProvider {
+	return &MockProvider{pepper: pepper}
+}
+
+// Verify implements Provider
+func (p *MockProvider) Verify(ctx context.Context, vnin, consentToken string) (*Result, error) {
+	if normalizeVNIN(vnin) == "" {
+		return nil, ErrInvalidVNIN
+	}
+	if consentToken == "" {
+		return nil, fmt.Errorf("%w: missing consent token", ErrConsentRejected)
+	}
+
+	return &Result{
+		FullName:   "Mock Verified User",
+		VNINHash:   HashVNIN(vnin, p.pepper),
+		VerifiedAt: time.Now(),
+		Mock:       true,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package identity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// vNINs are 16 alphanumeric characters
+	vninPattern = regexp.MustCompile(`^[A-Z0-9]{16}$`)
+	// Consent tokens are opaque but must be a single printable token. Go
+	// caps repeat counts at 1000, so the upper bound is checked separately.
+	consentPattern = regexp.MustCompile(`^[A-Za-z0-9._~+/=-]{8,}$`)
+)
+
+// maxConsentTokenLen bounds the consent token forwarded to NIMC
+const maxConsentTokenLen = 2048
+
+// NIMCProvider verifies vNINs against the NIMC vNIN HTTP API
+type NIMCProvider struct {
+	baseURL    string
+	apiKey     string
+	agentID    string
+	pepper     string
+	httpClient *http.Client
+}
+
+// NewNIMCProvider creates a client for the NIMC vNIN API at baseURL
+func NewNIMCProvider(baseURL, apiKey, agentID, pepper string, timeout time.Duration) *NIMCProvider {
+	return &NIMCProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		agentID:    agentID,
+		pepper:     pepper,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type nimcVerifyRequest struct {
+	VNIN         string `json:"vnin"`
+	ConsentToken string `json:"consentToken"`
+	AgentID      string `json:"agentId,omitempty"`
+	RequestID    string `json:"requestId"`
+}
+
+type nimcVerifyResponse struct {
+	Status  string `json:"status"` // verified, not_found, consent_invalid
+	Message string `json:"message"`
+	Data    struct {
+		FirstName  string `json:"firstName"`
+		MiddleName string `json:"middleName"`
+		LastName   string `json:"lastName"`
+	} `json:"data"`
+}
+
+// Verify implements Provider
+func (p *NIMCProvider) Verify(ctx context.Context, vnin, consentToken string) (*Result, error) {
+	vnin = normalizeVNIN(vnin)
+	if !vninPattern.MatchString(vnin) {
+		return nil, ErrInvalidVNIN
+	}
+	if len(consentToken) > maxConsentTokenLen || !consentPattern.MatchString(consentToken) {
+		return nil, fmt.Errorf("%w: malformed consent token", ErrConsentRejected)
+	}
+
+	body, err := json.Marshal(nimcVerifyRequest{
+		VNIN:         vnin,
+		ConsentToken: consentToken,
+		AgentID:      p.agentID,
+		RequestID:    uuid.New().String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode NIMC request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/vnin/verify", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build NIMC request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var out nimcVerifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("%w: malformed response: %v", ErrProviderUnavailable, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK && out.Status == "verified":
+		// fall through to success
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden, out.Status == "consent_invalid":
+		return nil, fmt.Errorf("%w: %s", ErrConsentRejected, out.Message)
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusUnprocessableEntity, out.Status == "not_found":
+		return nil, ErrInvalidVNIN
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+
+	nameParts := []string{}
+	for _, part := range []string{out.Data.FirstName, out.Data.MiddleName, out.Data.LastName} {
+		if part = strings.TrimSpace(part); part != "" {
+			nameParts = append(nameParts, part)
+		}
+	}
+
+	return &Result{
+		FullName:   strings.Join(nameParts, " "),
+		VNINHash:   HashVNIN(vnin, p.pepper),
+		VerifiedAt: time.Now(),
+	}, nil
+}
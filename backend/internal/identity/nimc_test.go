@@ -0,0 +1,148 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	testVNIN    = "AB12CD34EF56GH78"
+	testConsent = "consent-token-123"
+	testAPIKey  = "test-api-key"
+	testPepper  = "test-pepper"
+)
+
+// nimcStandIn serves /v1/vnin/verify with the given status and body, after
+// checking the request the provider sent
+func nimcStandIn(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/vnin/verify" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer "+testAPIKey {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req nimcVerifyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		if req.VNIN != testVNIN || req.ConsentToken != testConsent || req.AgentID != "agent-1" || req.RequestID == "" {
+			t.Errorf("unexpected request %+v", req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNIMCProviderVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{
+			name:   "verified",
+			status: http.StatusOK,
+			body:   `{"status": "verified", "data": {"firstName": "Ada", "middleName": " ", "lastName": "Obi"}}`,
+		},
+		{
+			name:    "not found",
+			status:  http.StatusNotFound,
+			body:    `{"status": "not_found", "message": "no record"}`,
+			wantErr: ErrInvalidVNIN,
+		},
+		{
+			name:    "not found with 200",
+			status:  http.StatusOK,
+			body:    `{"status": "not_found"}`,
+			wantErr: ErrInvalidVNIN,
+		},
+		{
+			name:    "invalid consent token",
+			status:  http.StatusOK,
+			body:    `{"status": "consent_invalid", "message": "expired"}`,
+			wantErr: ErrConsentRejected,
+		},
+		{
+			name:    "consent refused",
+			status:  http.StatusForbidden,
+			body:    `{"message": "forbidden"}`,
+			wantErr: ErrConsentRejected,
+		},
+		{
+			name:    "server error",
+			status:  http.StatusBadGateway,
+			body:    `upstream down`,
+			wantErr: ErrProviderUnavailable,
+		},
+		{
+			name:    "malformed JSON",
+			status:  http.StatusOK,
+			body:    `{"status": "verified"`,
+			wantErr: ErrProviderUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := nimcStandIn(t, tt.status, tt.body)
+			p := NewNIMCProvider(srv.URL+"/", testAPIKey, "agent-1", testPepper, 5*time.Second)
+
+			result, err := p.Verify(context.Background(), " "+testVNIN+" ", testConsent)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if result.FullName != "Ada Obi" {
+				t.Errorf("FullName = %q", result.FullName)
+			}
+			if result.Mock {
+				t.Error("NIMC result marked as mock")
+			}
+			if want := HashVNIN(testVNIN, testPepper); result.VNINHash != want {
+				t.Errorf("VNINHash = %s, want the peppered hash %s", result.VNINHash, want)
+			}
+			if result.VNINHash == HashVNIN(testVNIN, "other-pepper") {
+				t.Error("VNINHash does not depend on the pepper")
+			}
+		})
+	}
+}
+
+func TestNIMCProviderRejectsMalformedInput(t *testing.T) {
+	p := NewNIMCProvider("http://127.0.0.1:0", testAPIKey, "", testPepper, time.Second)
+
+	if _, err := p.Verify(context.Background(), "short", testConsent); !errors.Is(err, ErrInvalidVNIN) {
+		t.Errorf("malformed vNIN: err = %v", err)
+	}
+	if _, err := p.Verify(context.Background(), testVNIN, "bad token"); !errors.Is(err, ErrConsentRejected) {
+		t.Errorf("malformed consent token: err = %v", err)
+	}
+}
+
+func TestNIMCProviderUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	p := NewNIMCProvider(url, testAPIKey, "", testPepper, time.Second)
+	if _, err := p.Verify(context.Background(), testVNIN, testConsent); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("err = %v, want ErrProviderUnavailable", err)
+	}
+}
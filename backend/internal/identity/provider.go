@@ -0,0 +1,88 @@
+// Package identity verifies Nigerian virtual NINs (vNIN) against NIMC.
+package identity
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/inkless/backend/internal/config"
+)
+
+var (
+	// ErrInvalidVNIN is returned when the vNIN is malformed or unknown to NIMC
+	ErrInvalidVNIN = errors.New("invalid vNIN")
+	// ErrConsentRejected is returned when the consent token is malformed, expired or refused
+	ErrConsentRejected = errors.New("consent token rejected")
+	// ErrProviderUnavailable is returned when the upstream identity service can't be reached
+	ErrProviderUnavailable = errors.New("identity provider unavailable")
+)
+
+// Result is the outcome of a successful vNIN verification.
+// The raw vNIN never leaves the provider; only its hash does.
+type Result struct {
+	FullName   string
+	VNINHash   string
+	VerifiedAt time.Time
+	Mock       bool
+}
+
+// Provider verifies a vNIN with the holder's consent token
+type Provider interface {
+	Verify(ctx context.Context, vnin, consentToken string) (*Result, error)
+}
+
+// NewProvider picks the implementation from config: the HTTP NIMC client when
+// NIMC_API_ENABLED is set and NIMC_MOCK_MODE is off, the mock otherwise.
+// Production refuses the mock.
+func NewProvider(cfg *config.Config) (Provider, error) {
+	pepper := cfg.VNINHashPepper
+	if pepper == "" {
+		if cfg.Environment == "production" {
+			return nil, fmt.Errorf("VNIN_HASH_PEPPER must be set in production")
+		}
+		log.Println("[Identity] VNIN_HASH_PEPPER not set, using insecure development pepper")
+		pepper = devPepper
+	}
+
+	if !cfg.NIMCAPIEnabled || cfg.NIMCMockMode {
+		if cfg.Environment == "production" {
+			// The mock verifies any vNIN, so every caller would get a grant
+			return nil, fmt.Errorf("the mock NIMC provider can't be used in production: set NIMC_API_ENABLED=true and NIMC_MOCK_MODE=false")
+		}
+		log.Println("[Identity] Using mock NIMC provider")
+		return NewMockProvider(pepper), nil
+	}
+
+	if cfg.NIMCAPIURL == "" || cfg.NIMCAPIKey == "" {
+		return nil, fmt.Errorf("NIMC API enabled but NIMC_API_URL or NIMC_API_KEY is not set")
+	}
+
+	log.Printf("[Identity] Using NIMC vNIN API at %s", cfg.NIMCAPIURL)
+	return NewNIMCProvider(cfg.NIMCAPIURL, cfg.NIMCAPIKey, cfg.NIMCAgentID, pepper, cfg.NIMCTimeout), nil
+}
+
+// vninHashSalt domain-separates vNIN hashes from any other HMAC keyed with the pepper
+const vninHashSalt = "inkless:vnin:v1:"
+
+// devPepper is only used outside production when VNIN_HASH_PEPPER is unset
+const devPepper = "inkless-development-pepper"
+
+// HashVNIN returns HMAC-SHA256(pepper, salt || vNIN) in hex. The pepper is a
+// server-held secret, so a leaked users table can't be brute-forced over the
+// small vNIN space; the hash stays deterministic so it can be looked up.
+func HashVNIN(vnin, pepper string) string {
+	mac := hmac.New(sha256.New, []byte(pepper))
+	mac.Write([]byte(vninHashSalt + normalizeVNIN(vnin)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func normalizeVNIN(vnin string) string {
+	return strings.ToUpper(strings.TrimSpace(vnin))
+}
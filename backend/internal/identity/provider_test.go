@@ -0,0 +1,37 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/inkless/backend/internal/config"
+)
+
+func TestNewProviderRefusesMockInProduction(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr bool
+	}{
+		{"mock mode in production", config.Config{Environment: "production", VNINHashPepper: testPepper, NIMCAPIEnabled: true, NIMCMockMode: true}, true},
+		{"API disabled in production", config.Config{Environment: "production", VNINHashPepper: testPepper}, true},
+		{"no pepper in production", config.Config{Environment: "production", NIMCAPIEnabled: true, NIMCAPIURL: "http://nimc", NIMCAPIKey: testAPIKey}, true},
+		{"NIMC in production", config.Config{Environment: "production", VNINHashPepper: testPepper, NIMCAPIEnabled: true, NIMCAPIURL: "http://nimc", NIMCAPIKey: testAPIKey}, false},
+		{"mock in development", config.Config{Environment: "development", NIMCMockMode: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			p, err := NewProvider(&cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %T, want an error", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package indexer
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"gorm.io/gorm"
+)
+
+// Drift kinds
+const (
+	DriftMissingOnChain = "missing_on_chain" // Postgres says anchored; the contract has no record
+	DriftMissingInDB    = "missing_in_db"    // The contract has a record no signature row accounts for
+)
+
+// rematchLimit bounds the unmatched anchors retried per drift check
+const rematchLimit = 500
+
+// checkDrift compares the mirror with signature_metadata up to the
+// checkpoint, opening a ledger_drifts row for each new disagreement and
+// resolving rows whose disagreement has gone away
+func (i *Indexer) checkDrift(ctx context.Context) error {
+	cp, err := i.checkpoint()
+	if err != nil || cp == nil {
+		return err
+	}
+	tx := i.db.WithContext(ctx)
+
+	// A signature row written (or restored) after its anchor was indexed
+	if err := rematch(tx); err != nil {
+		return err
+	}
+
+	// Anchors no signature accounts for; batch roots are accounted for by their members
+	var orphans []models.LedgerAnchor
+	batchRoots := tx.Model(&models.SignatureMetadata{}).Select("merkle_root").Where("merkle_root <> ''")
+	if err := tx.Select("id, doc_hash").
+		Where("signature_id IS NULL AND anchored_at IS NOT NULL").
+		Where("doc_hash NOT IN (?)", batchRoots).
+		Find(&orphans).Error; err != nil {
+		return err
+	}
+
+	// Anchored signatures below the checkpoint with no record on-chain; rows
+	// anchored on another network are not in this mirror
+	var missing []models.SignatureMetadata
+	matched := tx.Model(&models.LedgerAnchor{}).Select("signature_id").Where("signature_id IS NOT NULL")
+	anchoredRoots := tx.Model(&models.LedgerAnchor{}).Select("doc_hash").Where("anchored_at IS NOT NULL")
+	if err := tx.Select("id, doc_hash").
+		Where("(status IN ? OR (status = ? AND block_number IS NOT NULL))",
+			[]string{anchoring.SignatureAnchored, "verified"}, anchoring.SignatureRevoked).
+		Where("COALESCE(ledger_network, '') IN ?", []string{"", ledger.PrimaryNetwork()}).
+		Where("(block_number IS NULL OR block_number <= ?)", cp.BlockNumber).
+		Where("id NOT IN (?)", matched).
+		Where("(merkle_root IS NULL OR merkle_root = '' OR merkle_root NOT IN (?))", anchoredRoots).
+		Find(&missing).Error; err != nil {
+		return err
+	}
+
+	current := make(map[string]models.LedgerDrift, len(orphans)+len(missing))
+	for _, a := range orphans {
+		id := a.ID
+		current[DriftMissingInDB+":"+id.String()] = models.LedgerDrift{Kind: DriftMissingInDB, DocHash: a.DocHash, LedgerAnchorID: &id}
+	}
+	for _, s := range missing {
+		id := s.ID
+		current[DriftMissingOnChain+":"+id.String()] = models.LedgerDrift{Kind: DriftMissingOnChain, DocHash: s.DocHash, SignatureID: &id}
+	}
+
+	return tx.Transaction(func(tx *gorm.DB) error {
+		var open []models.LedgerDrift
+		if err := tx.Where("resolved_at IS NULL").Find(&open).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var resolved []uuid.UUID
+		for _, d := range open {
+			key := driftKey(&d)
+			if _, still := current[key]; still {
+				delete(current, key)
+				continue
+			}
+			resolved = append(resolved, d.ID)
+		}
+		if len(resolved) > 0 {
+			if err := tx.Model(&models.LedgerDrift{}).Where("id IN ?", resolved).Update("resolved_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, d := range current {
+			d.DetectedAt = now
+			if err := tx.Create(&d).Error; err != nil {
+				return err
+			}
+			log.Printf("[Indexer] Drift: %s for %s", d.Kind, d.DocHash)
+		}
+		if len(current) > 0 || len(resolved) > 0 {
+			log.Printf("[Indexer] Drift check: %d new, %d resolved, %d open", len(current), len(resolved), len(open)-len(resolved)+len(current))
+		}
+		return nil
+	})
+}
+
+// rematch retries matching anchors that had no signature row when indexed
+func rematch(tx *gorm.DB) error {
+	var unmatched []models.LedgerAnchor
+	if err := tx.Where("signature_id IS NULL AND anchored_at IS NOT NULL").
+		Order("block_number").
+		Limit(rematchLimit).
+		Find(&unmatched).Error; err != nil {
+		return err
+	}
+	for i := range unmatched {
+		if id := matchSignature(tx, &unmatched[i]); id != nil {
+			if err := tx.Model(&unmatched[i]).Update("signature_id", id).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// driftKey identifies what a drift row is about
+func driftKey(d *models.LedgerDrift) string {
+	if d.SignatureID != nil {
+		return d.Kind + ":" + d.SignatureID.String()
+	}
+	if d.LedgerAnchorID != nil {
+		return d.Kind + ":" + d.LedgerAnchorID.String()
+	}
+	return d.Kind
+}
@@ -0,0 +1,272 @@
+// Package indexer follows InklessRegistry events into Postgres. Every log is
+// kept in ledger_events; ledger_anchors and ledger_signers mirror the
+// contract's state; ledger_drifts flags rows the chain and Postgres disagree on.
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Options tune the indexer
+type Options struct {
+	PollInterval  time.Duration // How often to look for new blocks
+	Confirmations int           // Only blocks this deep are indexed
+	StartBlock    uint64        // First block to index; the contract's deployment block
+	MaxBlockRange uint64        // Blocks per FilterLogs call
+	ReorgDepth    uint64        // How far to rewind when the checkpoint block was reorged away
+	DriftInterval time.Duration // How often to compare the mirror with signature_metadata
+}
+
+// Indexer follows the contract's logs from a checkpoint
+type Indexer struct {
+	db       *gorm.DB
+	opts     Options
+	contract string
+}
+
+// New creates an indexer for the ledger's contract, filling in defaults for unset options
+func New(database *gorm.DB, contract string, opts Options) *Indexer {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 15 * time.Second
+	}
+	if opts.Confirmations <= 0 {
+		opts.Confirmations = 12
+	}
+	if opts.MaxBlockRange == 0 {
+		opts.MaxBlockRange = 2000
+	}
+	if opts.ReorgDepth == 0 {
+		opts.ReorgDepth = uint64(opts.Confirmations) * 4
+	}
+	if opts.DriftInterval <= 0 {
+		opts.DriftInterval = 10 * time.Minute
+	}
+	return &Indexer{db: database, opts: opts, contract: contract}
+}
+
+// Run indexes new blocks until ctx is cancelled. A log subscription, where
+// the node supports one, only shortens the wait between polls.
+func (i *Indexer) Run(ctx context.Context) {
+	log.Printf("[Indexer] Started for %s (poll every %s, %d confirmations)", i.contract, i.opts.PollInterval, i.opts.Confirmations)
+	if err := i.claimLegacyEvents(); err != nil {
+		log.Printf("[Indexer] Could not assign events indexed before contracts were recorded: %v", err)
+	}
+
+	notify := make(chan struct{}, 1)
+	var subErr <-chan error
+	if sub, err := ledger.Global.SubscribeEvents(ctx, notify); err != nil {
+		log.Printf("[Indexer] No log subscription, polling only: %v", err)
+	} else {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
+
+	ticker := time.NewTicker(i.opts.PollInterval)
+	defer ticker.Stop()
+
+	var lastDrift time.Time
+	for {
+		caughtUp, err := i.sync(ctx)
+		if err != nil {
+			log.Printf("[Indexer] Sync failed: %v", err)
+		}
+		if caughtUp && time.Since(lastDrift) >= i.opts.DriftInterval {
+			if err := i.checkDrift(ctx); err != nil {
+				log.Printf("[Indexer] Drift check failed: %v", err)
+			}
+			lastDrift = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("[Indexer] Stopped")
+			return
+		case <-ticker.C:
+		case <-notify:
+		case err := <-subErr:
+			log.Printf("[Indexer] Log subscription ended, polling only: %v", err)
+			subErr = nil
+		}
+	}
+}
+
+// sync indexes every confirmed block past the checkpoint and reports
+// whether it reached the confirmed head
+func (i *Indexer) sync(ctx context.Context) (bool, error) {
+	head, err := ledger.Global.HeadBlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	if head < uint64(i.opts.Confirmations) {
+		return true, nil
+	}
+	safe := head - uint64(i.opts.Confirmations)
+
+	from := i.opts.StartBlock
+	cp, err := i.checkpoint()
+	if err != nil {
+		return false, err
+	}
+	if cp != nil {
+		hash, err := ledger.Global.BlockHash(ctx, cp.BlockNumber)
+		if err != nil {
+			return false, err
+		}
+		if hash != cp.BlockHash {
+			if err := i.rewind(ctx, cp); err != nil {
+				return false, fmt.Errorf("rewind after reorg: %w", err)
+			}
+			if cp, err = i.checkpoint(); err != nil {
+				return false, err
+			}
+		}
+		if cp != nil {
+			from = cp.BlockNumber + 1
+		}
+	}
+
+	for from <= safe {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+		to := min(from+i.opts.MaxBlockRange-1, safe)
+		if err := i.indexRange(ctx, from, to); err != nil {
+			return false, err
+		}
+		from = to + 1
+	}
+	return true, nil
+}
+
+// indexRange stores and applies the events in from..to and advances the checkpoint
+func (i *Indexer) indexRange(ctx context.Context, from, to uint64) error {
+	events, err := ledger.Global.FilterEvents(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	toHash, err := ledger.Global.BlockHash(ctx, to)
+	if err != nil {
+		return err
+	}
+
+	err = i.db.Transaction(func(tx *gorm.DB) error {
+		for _, e := range events {
+			row := eventRow(i.contract, e)
+			created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+			if created.Error != nil {
+				return created.Error
+			}
+			if created.RowsAffected == 0 {
+				continue // Already indexed
+			}
+			if err := apply(tx, &row); err != nil {
+				return err
+			}
+		}
+		return i.saveCheckpoint(tx, to, toHash)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(events) > 0 {
+		log.Printf("[Indexer] Indexed %d events in blocks %d-%d", len(events), from, to)
+	}
+	return nil
+}
+
+// rewind discards the contract's events indexed above the last block that is
+// still canonical, at most ReorgDepth blocks below the checkpoint, and
+// rebuilds the mirror rows those events touched
+func (i *Indexer) rewind(ctx context.Context, cp *models.LedgerCheckpoint) error {
+	target := i.opts.StartBlock
+	if cp.BlockNumber > i.opts.ReorgDepth && cp.BlockNumber-i.opts.ReorgDepth > target {
+		target = cp.BlockNumber - i.opts.ReorgDepth
+	}
+	log.Printf("[Indexer] Block %d (%s) was reorged away; rewinding to %d", cp.BlockNumber, cp.BlockHash, target)
+
+	var targetHash string
+	if target > i.opts.StartBlock {
+		hash, err := ledger.Global.BlockHash(ctx, target-1)
+		if err != nil {
+			return err
+		}
+		targetHash = hash
+	}
+
+	return i.db.Transaction(func(tx *gorm.DB) error {
+		var stale []models.LedgerEvent
+		if err := tx.Where("contract = ? AND block_number >= ?", i.contract, target).Find(&stale).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("contract = ? AND block_number >= ?", i.contract, target).Delete(&models.LedgerEvent{}).Error; err != nil {
+			return err
+		}
+		if err := rebuild(tx, i.contract, stale); err != nil {
+			return err
+		}
+
+		if target <= i.opts.StartBlock {
+			return tx.Where("contract = ?", i.contract).Delete(&models.LedgerCheckpoint{}).Error
+		}
+		return i.saveCheckpoint(tx, target-1, targetHash)
+	})
+}
+
+// claimLegacyEvents assigns events indexed before their contract was
+// recorded to the contract that held the only checkpoint. With several
+// checkpoints their origin is unknown, and they are left unassigned.
+func (i *Indexer) claimLegacyEvents() error {
+	var checkpoints []models.LedgerCheckpoint
+	if err := i.db.Limit(2).Find(&checkpoints).Error; err != nil || len(checkpoints) != 1 {
+		return err
+	}
+	res := i.db.Model(&models.LedgerEvent{}).Where("contract = ''").Update("contract", checkpoints[0].Contract)
+	if res.RowsAffected > 0 {
+		log.Printf("[Indexer] Assigned %d earlier events to %s", res.RowsAffected, checkpoints[0].Contract)
+	}
+	return res.Error
+}
+
+// checkpoint loads the contract's checkpoint, or nil before the first pass
+func (i *Indexer) checkpoint() (*models.LedgerCheckpoint, error) {
+	var cp models.LedgerCheckpoint
+	err := i.db.Where("contract = ?", i.contract).Limit(1).Find(&cp).Error
+	if err != nil || cp.Contract == "" {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (i *Indexer) saveCheckpoint(tx *gorm.DB, block uint64, hash string) error {
+	return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.LedgerCheckpoint{
+		Contract:    i.contract,
+		BlockNumber: block,
+		BlockHash:   hash,
+	}).Error
+}
+
+// eventRow converts a decoded event of contract for storage
+func eventRow(contract string, e ledger.Event) models.LedgerEvent {
+	return models.LedgerEvent{
+		Contract:       contract,
+		BlockNumber:    e.BlockNumber,
+		BlockHash:      e.BlockHash,
+		TxHash:         e.TxHash,
+		LogIndex:       e.LogIndex,
+		Event:          e.Name,
+		DocHash:        e.DocHash,
+		SignerID:       e.SignerID,
+		Account:        e.Account,
+		HardwareID:     e.HardwareID,
+		ChainTimestamp: e.Timestamp,
+	}
+}
@@ -0,0 +1,150 @@
+package indexer
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// apply folds one event into the mirror tables. Events must be applied in
+// chain order.
+func apply(tx *gorm.DB, e *models.LedgerEvent) error {
+	switch e.Event {
+	case ledger.EventSignatureAnchored, ledger.EventSignerAnchored:
+		anchoredAt := e.ChainTimestamp
+		anchor := models.LedgerAnchor{
+			DocHash:     e.DocHash,
+			SignerID:    e.SignerID,
+			Submitter:   e.Account,
+			HardwareID:  e.HardwareID,
+			AnchoredAt:  &anchoredAt,
+			BlockNumber: e.BlockNumber,
+			TxHash:      e.TxHash,
+		}
+		anchor.SignatureID = matchSignature(tx, &anchor)
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "doc_hash"}, {Name: "signer_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"submitter", "hardware_id", "anchored_at", "block_number", "tx_hash", "signature_id", "updated_at"}),
+		}).Create(&anchor).Error
+
+	case ledger.EventSignatureRevoked, ledger.EventSignerRevoked:
+		revokedAt := e.ChainTimestamp
+		txHash := e.TxHash
+		// The anchor may predate StartBlock, in which case only the revocation is known
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "doc_hash"}, {Name: "signer_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revoked", "revoked_at", "revoke_tx_hash", "updated_at"}),
+		}).Create(&models.LedgerAnchor{
+			DocHash:      e.DocHash,
+			SignerID:     e.SignerID,
+			Revoked:      true,
+			RevokedAt:    &revokedAt,
+			RevokeTxHash: &txHash,
+		}).Error
+
+	case ledger.EventSignerVerified, ledger.EventSignerRemoved:
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.LedgerSigner{
+			Address:     e.Account,
+			Verified:    e.Event == ledger.EventSignerVerified,
+			BlockNumber: e.BlockNumber,
+		}).Error
+	}
+	return nil
+}
+
+// rebuild recomputes the mirror rows touched by events that were discarded
+// after a reorg, replaying the contract's events that remain for them
+func rebuild(tx *gorm.DB, contract string, discarded []models.LedgerEvent) error {
+	type anchorKey struct{ docHash, signerID string }
+	anchors := map[anchorKey]bool{}
+	signers := map[string]bool{}
+	for _, e := range discarded {
+		switch e.Event {
+		case ledger.EventSignerVerified, ledger.EventSignerRemoved:
+			signers[e.Account] = true
+		default:
+			anchors[anchorKey{e.DocHash, e.SignerID}] = true
+		}
+	}
+
+	for key := range anchors {
+		if err := tx.Where("doc_hash = ? AND signer_id = ?", key.docHash, key.signerID).Delete(&models.LedgerAnchor{}).Error; err != nil {
+			return err
+		}
+		var remaining []models.LedgerEvent
+		if err := tx.Where("contract = ? AND doc_hash = ? AND signer_id = ? AND event IN ?", contract, key.docHash, key.signerID,
+			[]string{ledger.EventSignatureAnchored, ledger.EventSignerAnchored, ledger.EventSignatureRevoked, ledger.EventSignerRevoked}).
+			Order("block_number, log_index").Find(&remaining).Error; err != nil {
+			return err
+		}
+		for i := range remaining {
+			if err := apply(tx, &remaining[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for address := range signers {
+		if err := tx.Where("address = ?", address).Delete(&models.LedgerSigner{}).Error; err != nil {
+			return err
+		}
+		var remaining []models.LedgerEvent
+		if err := tx.Where("contract = ? AND account = ? AND event IN ?", contract, address,
+			[]string{ledger.EventSignerVerified, ledger.EventSignerRemoved}).
+			Order("block_number, log_index").Find(&remaining).Error; err != nil {
+			return err
+		}
+		for i := range remaining {
+			if err := apply(tx, &remaining[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchSignature finds the Postgres signature an on-chain anchor records.
+// The API records the tx hash of everything it anchors; failing that, a
+// per-signer record matches on document and keccak256(DID), and a
+// document-keyed one on the document's first signer. Batch roots, which
+// anchor many signatures in one tx, match none.
+func matchSignature(tx *gorm.DB, anchor *models.LedgerAnchor) *uuid.UUID {
+	var byTx []models.SignatureMetadata
+	tx.Select("id").Where("ledger_tx_hash = ?", anchor.TxHash).Limit(2).Find(&byTx)
+	switch len(byTx) {
+	case 1:
+		return &byTx[0].ID
+	case 2:
+		return nil
+	}
+
+	var candidates []models.SignatureMetadata
+	tx.Preload("Signer").
+		Where("lower(doc_hash) IN ?", docHashForms(anchor.DocHash)).
+		Order("created_at asc").
+		Find(&candidates)
+	if len(candidates) == 0 {
+		return nil
+	}
+	if anchor.SignerID == "" {
+		return &candidates[0].ID
+	}
+	for i := range candidates {
+		if ledger.SignerID(candidates[i].Signer.DIDAddress).Hex() == anchor.SignerID {
+			return &candidates[i].ID
+		}
+	}
+	return nil
+}
+
+// docHashForms lists the spellings of an on-chain docHash that clients may
+// have stored: with or without 0x, in lower case
+func docHashForms(docHash string) []string {
+	hex := strings.ToLower(strings.TrimPrefix(common.HexToHash(docHash).Hex(), "0x"))
+	return []string{hex, "0x" + hex}
+}
@@ -0,0 +1,211 @@
+package ledger
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer accounts give every DID its own secp256k1 account, derived from a
+// seed the server holds. The account signs EIP-712 approval of each of its
+// signer's anchors; the relayer still sends and pays for the transaction,
+// but the contract checks the approval and records the account, so an
+// anchor is attributable to its signer rather than to the relayer. An
+// account only approves anchors once registered through addVerifiedSigner.
+//
+// These keys are in server custody: anyone holding the seed can approve as
+// any signer, so an approval shows which verified identity the server
+// anchored for, not that the signer's device consented. It is no evidence
+// of non-repudiation; the signer's own PQC signature in the payload is.
+// Batch roots are approved by no one, which is why batching is refused
+// while signer accounts are configured.
+var (
+	accountsMu  sync.RWMutex
+	accountSeed []byte
+)
+
+// minAccountSeedLen is the shortest seed signer accounts are derived from
+const minAccountSeedLen = 32
+
+// LoadAccountSeed reads the signer account seed: hex inline (development
+// only) or from a file holding it. Neither set disables signer accounts.
+func LoadAccountSeed(seedHex, seedFile string) ([]byte, error) {
+	if seedHex != "" && seedFile != "" {
+		return nil, errors.New("configure a signer account seed or a seed file, not both")
+	}
+	if seedFile != "" {
+		raw, err := os.ReadFile(seedFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signer account seed: %w", err)
+		}
+		seedHex = strings.TrimSpace(string(raw))
+	}
+	if seedHex == "" {
+		return nil, nil
+	}
+
+	seed, err := hex.DecodeString(strings.TrimPrefix(seedHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("signer account seed is not hex: %w", err)
+	}
+	if len(seed) < minAccountSeedLen {
+		return nil, fmt.Errorf("signer account seed must be at least %d bytes", minAccountSeedLen)
+	}
+	return seed, nil
+}
+
+// ConfigureAccounts sets the seed signer accounts are derived from; nil
+// disables them, leaving every anchor vouched for by the relayer alone.
+// Changing the seed changes every account, so it must never be rotated
+// without re-registering them.
+func ConfigureAccounts(seed []byte) {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+	accountSeed = append([]byte(nil), seed...)
+}
+
+// AccountsEnabled reports whether signer accounts are configured
+func AccountsEnabled() bool {
+	accountsMu.RLock()
+	defer accountsMu.RUnlock()
+	return len(accountSeed) > 0
+}
+
+// ErrNoAccounts is returned when signer accounts aren't configured
+var ErrNoAccounts = errors.New("signer accounts are not configured")
+
+// AccountAddress returns the account derived for did
+func AccountAddress(did string) (string, error) {
+	key, err := accountKey(did)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(key.PublicKey).Hex(), nil
+}
+
+// accountKey derives did's key as HMAC-SHA256(seed, did), rehashing in the
+// vanishingly rare case that isn't a valid secp256k1 key. The server can
+// derive it at will; the device never sees it.
+func accountKey(did string) (*ecdsa.PrivateKey, error) {
+	accountsMu.RLock()
+	seed := accountSeed
+	accountsMu.RUnlock()
+	if len(seed) == 0 {
+		return nil, ErrNoAccounts
+	}
+
+	mac := hmac.New(sha256.New, seed)
+	mac.Write([]byte(did))
+	material := mac.Sum(nil)
+	for {
+		if key, err := crypto.ToECDSA(material); err == nil {
+			return key, nil
+		}
+		mac.Reset()
+		mac.Write(material)
+		material = mac.Sum(nil)
+	}
+}
+
+// EIP-712 type hashes of the registry's domain and of the Anchor approval
+var (
+	eip712DomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	anchorTypeHash       = crypto.Keccak256Hash([]byte("Anchor(bytes32 docHash,bytes32 signerId,bytes32 signatureHash,bytes32 hardwareID)"))
+)
+
+// domainSeparator is the registry's EIP-712 domain separator on chainID
+func domainSeparator(chainID *big.Int, contract common.Address) common.Hash {
+	return crypto.Keccak256Hash(
+		eip712DomainTypeHash.Bytes(),
+		crypto.Keccak256([]byte("InklessRegistry")),
+		crypto.Keccak256([]byte("1")),
+		common.BigToHash(chainID).Bytes(),
+		common.BytesToHash(contract.Bytes()).Bytes(),
+	)
+}
+
+// anchorDigest is the EIP-712 digest a signer account signs to approve
+// anchoring signature for docHash
+func anchorDigest(domain, docHash, signerID common.Hash, signature []byte, hardwareID common.Hash) common.Hash {
+	structHash := crypto.Keccak256Hash(
+		anchorTypeHash.Bytes(),
+		docHash.Bytes(),
+		signerID.Bytes(),
+		crypto.Keccak256(signature),
+		hardwareID.Bytes(),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain.Bytes(), structHash.Bytes())
+}
+
+// approveAnchor signs did's account's approval of an anchor under domain,
+// returning the account and its 65-byte signature with v as 27 or 28
+func approveAnchor(did string, domain, docHash common.Hash, signature []byte, hardwareID common.Hash) (common.Address, []byte, error) {
+	key, err := accountKey(did)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	sig, err := crypto.Sign(anchorDigest(domain, docHash, SignerID(did), signature, hardwareID).Bytes(), key)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to sign anchor approval: %w", err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return crypto.PubkeyToAddress(key.PublicKey), sig, nil
+}
+
+// supportsAccounts reads the contract's EIP-712 domain; contracts without
+// anchorSignerSignatureFor revert. A domain that differs from the one
+// derived here would make every approval fail, so it counts as unsupported.
+func (c *Client) supportsAccounts(ctx context.Context) (bool, error) {
+	domain, err := c.registry.DomainSeparator(ctx)
+	if supported, err := probeResult(err); !supported {
+		return false, err
+	}
+	return common.Hash(domain) == c.domain, nil
+}
+
+// accountApproval has did's account approve an anchor if the contract takes
+// approvals and the account is a verified signer. It returns a nil approval
+// otherwise, and the anchor is vouched for by the relayer alone.
+func (c *Client) accountApproval(ctx context.Context, did string, docHash common.Hash, signature []byte, hardwareID common.Hash) (common.Address, []byte, error) {
+	if !c.accounts || !AccountsEnabled() {
+		return common.Address{}, nil, nil
+	}
+	address, err := AccountAddress(did)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	verified, err := c.registry.IsVerifiedSigner(ctx, common.HexToAddress(address))
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	if !verified {
+		return common.Address{}, nil, nil
+	}
+	return approveAnchor(did, c.domain, docHash, signature, hardwareID)
+}
+
+// signerAccount returns the account that approved a signer's anchor, or ""
+func (c *Client) signerAccount(ctx context.Context, docHash, signerID common.Hash) (string, error) {
+	if !c.accounts {
+		return "", nil
+	}
+	account, err := c.registry.SignerAccounts(ctx, docHash, signerID)
+	if err != nil {
+		return "", err
+	}
+	if account == (common.Address{}) {
+		return "", nil
+	}
+	return account.Hex(), nil
+}
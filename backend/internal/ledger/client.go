@@ -2,62 +2,47 @@ package ledger
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"log"
 	"math/big"
-	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-// InklessRegistry ABI (simplified for anchorSignature function)
-const InklessRegistryABI = `[
-	{
-		"inputs": [
-			{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"},
-			{"internalType": "bytes", "name": "_pqcSignature", "type": "bytes"},
-			{"internalType": "bytes32", "name": "_hardwareID", "type": "bytes32"}
-		],
-		"name": "anchorSignature",
-		"outputs": [],
-		"stateMutability": "nonpayable",
-		"type": "function"
-	},
-	{
-		"inputs": [{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"}],
-		"name": "verifySignature",
-		"outputs": [
-			{"internalType": "bool", "name": "isValid", "type": "bool"},
-			{"internalType": "address", "name": "signerDID", "type": "address"},
-			{"internalType": "uint256", "name": "timestamp", "type": "uint256"},
-			{"internalType": "bytes32", "name": "hardwareID", "type": "bytes32"}
-		],
-		"stateMutability": "view",
-		"type": "function"
-	}
-]`
-
 // Client wraps the Ethereum client and contract interaction
 type Client struct {
 	ethClient       *ethclient.Client
 	contractAddress common.Address
-	privateKey      *ecdsa.PrivateKey
+	txSigner        TxSigner
 	chainID         *big.Int
-	contractABI     abi.ABI
+	registry        *Registry
+	from            common.Address
+	nonces          *NonceManager
+	fees            FeeConfig
+	multiSigner     bool        // Contract has anchorSignerSignature/getDocumentSigners
+	accounts        bool        // Contract takes signer account approvals (anchorSignerSignatureFor)
+	domain          common.Hash // The contract's EIP-712 domain separator
 }
 
-// NewClient creates a new ledger client
-func NewClient(rpcURL, contractAddr, privateKeyHex string) (*Client, error) {
+// NewClient creates a new ledger client sending from txSigner's account
+func NewClient(rpcURL, contractAddr string, txSigner TxSigner, fees FeeConfig) (_ *Client, err error) {
 	// Connect to Ethereum node
 	client, err := ethclient.Dial(rpcURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
 	}
+	// The supervisor redials a failing node in a loop; don't leak a connection per attempt
+	defer func() {
+		if err != nil {
+			client.Close()
+		}
+	}()
 
 	// Get chain ID
 	chainID, err := client.ChainID(context.Background())
@@ -65,133 +50,353 @@ func NewClient(rpcURL, contractAddr, privateKeyHex string) (*Client, error) {
 		return nil, fmt.Errorf("failed to get chain ID: %w", err)
 	}
 
-	// Parse private key (remove 0x prefix if present)
-	privateKeyHex = strings.TrimPrefix(privateKeyHex, "0x")
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		return nil, fmt.Errorf("invalid private key: %w", err)
-	}
-
 	// Parse contract address
 	if !common.IsHexAddress(contractAddr) {
 		return nil, fmt.Errorf("invalid contract address: %s", contractAddr)
 	}
 
-	// Parse ABI
-	parsedABI, err := abi.JSON(strings.NewReader(InklessRegistryABI))
+	// Bind the contract
+	registry, err := NewRegistry(common.HexToAddress(contractAddr), client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+		return nil, err
+	}
+
+	// Sync the nonce now so transactions still pending from a previous run are skipped
+	from := txSigner.Address()
+	nonces := NewNonceManager(client, from)
+	if err := nonces.Resync(context.Background()); err != nil {
+		return nil, err
 	}
 
-	return &Client{
+	c := &Client{
 		ethClient:       client,
 		contractAddress: common.HexToAddress(contractAddr),
-		privateKey:      privateKey,
+		txSigner:        txSigner,
 		chainID:         chainID,
-		contractABI:     parsedABI,
-	}, nil
+		registry:        registry,
+		from:            from,
+		nonces:          nonces,
+		fees:            fees.withDefaults(),
+		domain:          domainSeparator(chainID, common.HexToAddress(contractAddr)),
+	}
+	// The contract's features decide how anchors are packed for the life of
+	// the client, so a node that can't answer fails the connection and the
+	// supervisor redials, rather than falling back to legacy anchoring
+	if c.multiSigner, err = c.supportsMultiSigner(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to probe contract features: %w", err)
+	}
+	if !c.multiSigner {
+		log.Println("[Ledger] Contract predates multi-signer anchoring; anchoring under per-signer keys")
+	}
+	if c.multiSigner {
+		if c.accounts, err = c.supportsAccounts(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to probe contract features: %w", err)
+		}
+	}
+	if !c.accounts && AccountsEnabled() {
+		log.Println("[Ledger] Contract predates signer accounts; anchors are vouched for by the relayer alone")
+	}
+
+	return c, nil
 }
 
-// AnchorSignature submits a signature to the blockchain
-func (c *Client) AnchorSignature(ctx context.Context, docHash string, signature []byte, hardwareID string) (string, error) {
+// AnchorSignature submits one signer's signature to the blockchain. Each
+// (docHash, signer) pair gets its own record, so co-signers don't collide.
+// Once the signer's account is a verified signer, it approves the anchor and
+// the contract records it alongside the relayer that sent it.
+func (c *Client) AnchorSignature(ctx context.Context, docHash, signerDID string, signature []byte, hardwareID string) (string, error) {
 	// Convert docHash to bytes32 (pad or truncate to 32 bytes)
 	docHashBytes := common.HexToHash(docHash)
+	signerID := SignerID(signerDID)
+
+	hardwareIDBytes := HardwareIDHash(hardwareID)
+
+	// Pack the function call. Older deployments only have anchorSignature,
+	// keyed by document, so the signer is folded into the key instead.
+	var data []byte
+	var err error
+	if c.multiSigner {
+		account, approval, approvalErr := c.accountApproval(ctx, signerDID, docHashBytes, signature, hardwareIDBytes)
+		switch {
+		case approvalErr != nil:
+			return "", approvalErr
+		case approval != nil:
+			data, err = c.registry.PackAnchorSignerSignatureFor(docHashBytes, signerID, signature, hardwareIDBytes, account, approval)
+		default:
+			data, err = c.registry.PackAnchorSignerSignature(docHashBytes, signerID, signature, hardwareIDBytes)
+		}
+	} else {
+		data, err = c.registry.PackAnchorSignature(signerAnchorKey(docHashBytes, signerID), signature, hardwareIDBytes)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return c.transact(ctx, data)
+}
+
+// HardwareIDHash is the bytes32 a device's hardware ID is stored as on-chain
+func HardwareIDHash(hardwareID string) common.Hash {
+	return crypto.Keccak256Hash([]byte(hardwareID))
+}
 
-	// Convert hardwareID to bytes32
-	hardwareIDBytes := crypto.Keccak256Hash([]byte(hardwareID))
+// batchHardwareID marks batch-root anchors, which come from no device
+var batchHardwareID = HardwareIDHash("inkless-merkle-batch")
 
-	// Pack the function call
-	data, err := c.contractABI.Pack("anchorSignature", docHashBytes, signature, hardwareIDBytes)
+// AnchorBatchRoot anchors the Merkle root of a batch of signatures as a
+// document-keyed record; descriptor is stored in the signature field.
+func (c *Client) AnchorBatchRoot(ctx context.Context, root string, descriptor []byte) (string, error) {
+	data, err := c.registry.PackAnchorSignature(common.HexToHash(root), descriptor, batchHardwareID)
 	if err != nil {
-		return "", fmt.Errorf("failed to pack transaction data: %w", err)
+		return "", err
 	}
+	return c.transact(ctx, data)
+}
+
+// ErrNotAnchored is returned when revoking a signature the contract has no record of
+var ErrNotAnchored = errors.New("signature is not anchored on-chain")
+
+// RevokeSignature revokes one signer's anchor of docHash and returns the tx
+// hash. Documents anchored before per-signer anchoring have a single record
+// under the bare docHash; legacy allows falling back to it and should only be
+// set for the document's first signer, who owns that record.
+func (c *Client) RevokeSignature(ctx context.Context, docHash, signerDID string, legacy bool) (string, error) {
+	docHashBytes := common.HexToHash(docHash)
+	signerID := SignerID(signerDID)
 
-	// Get the sender address
-	publicKey := c.privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return "", fmt.Errorf("error casting public key to ECDSA")
+	var data []byte
+	var err error
+	switch record, lookupErr := c.SignerRecordOf(ctx, docHash, signerDID); {
+	case lookupErr != nil:
+		return "", lookupErr
+	case record != nil && c.multiSigner:
+		data, err = c.registry.PackRevokeSignerSignature(docHashBytes, signerID)
+	case record != nil:
+		data, err = c.registry.PackRevokeSignature(signerAnchorKey(docHashBytes, signerID))
+	default:
+		if !legacy {
+			return "", ErrNotAnchored
+		}
+		doc, lookupErr := c.documentRecord(ctx, docHashBytes)
+		if lookupErr != nil {
+			return "", lookupErr
+		}
+		if doc == nil {
+			return "", ErrNotAnchored
+		}
+		data, err = c.registry.PackRevokeSignature(docHashBytes)
 	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	if err != nil {
+		return "", err
+	}
+
+	return c.transact(ctx, data)
+}
+
+// ErrSignerUnchanged is returned when a signer is already in the requested state
+var ErrSignerUnchanged = errors.New("signer is already in that state")
 
-	// Get nonce
-	nonce, err := c.ethClient.PendingNonceAt(ctx, fromAddress)
+// SetVerifiedSigner adds address to the contract's verified signers, or
+// removes it, and returns the tx hash. Only the contract owner may, so the
+// relayer account must own the registry.
+func (c *Client) SetVerifiedSigner(ctx context.Context, address string, verified bool) (string, error) {
+	signer := common.HexToAddress(address)
+	current, err := c.registry.IsVerifiedSigner(ctx, signer)
 	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
+		return "", err
+	}
+	if current == verified {
+		return "", ErrSignerUnchanged
 	}
 
-	// Get gas price
-	gasPrice, err := c.ethClient.SuggestGasPrice(ctx)
+	var data []byte
+	if verified {
+		data, err = c.registry.PackAddVerifiedSigner(signer)
+	} else {
+		data, err = c.registry.PackRemoveVerifiedSigner(signer)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to suggest gas price: %w", err)
+		return "", err
 	}
+	return c.transact(ctx, data)
+}
 
-	// Create transaction
-	tx := types.NewTransaction(
-		nonce,
-		c.contractAddress,
-		big.NewInt(0),  // No ETH value
-		uint64(500000), // Gas limit
-		gasPrice,
-		data,
-	)
+// BatchRootRecord returns the on-chain record of an anchored batch root, or nil if it isn't anchored
+func (c *Client) BatchRootRecord(ctx context.Context, root string) (*SignerRecord, error) {
+	return c.documentRecord(ctx, common.HexToHash(root))
+}
 
-	// Sign transaction
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(c.chainID), c.privateKey)
+// transact sends a contract call from the signer account and returns its hash
+func (c *Client) transact(ctx context.Context, data []byte) (string, error) {
+	// Estimate gas (also surfaces a revert before anything is sent) and price
+	// the tx: EIP-1559 where the chain supports it, legacy otherwise
+	gas, err := c.estimateGas(ctx, data)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
+		return "", err
+	}
+	fees, err := c.suggestFees(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	// Send transaction
-	err = c.ethClient.SendTransaction(ctx, signedTx)
+	// Build, sign and send under the nonce manager so concurrent anchors never share a nonce
+	signedTx, err := c.nonces.Send(ctx, func(nonce uint64) (*types.Transaction, error) {
+		signed, err := c.txSigner.SignTx(ctx, c.newTx(nonce, gas, fees, data), c.chainID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign transaction: %w", err)
+		}
+		return signed, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		return "", err
 	}
 
 	return signedTx.Hash().Hex(), nil
 }
 
-// VerifySignature checks if a document hash exists on the blockchain
-func (c *Client) VerifySignature(ctx context.Context, docHash string) (bool, string, int64, error) {
-	docHashBytes := common.HexToHash(docHash)
+// ErrTxNotFound is returned when the node has neither a receipt nor a pending
+// transaction for a hash: it was dropped from the mempool or reorged out
+var ErrTxNotFound = errors.New("transaction not found")
+
+// Receipt is the on-chain outcome of a submitted transaction
+type Receipt struct {
+	Pending       bool // Known to the node but not yet mined
+	Reverted      bool
+	BlockNumber   uint64
+	BlockHash     string
+	BlockTime     time.Time
+	GasUsed       uint64
+	Confirmations uint64 // 1 when the block is the current head
+	Simulated     bool   // Produced by the simulated chain, not a real node
+}
 
-	// Pack call data
-	data, err := c.contractABI.Pack("verifySignature", docHashBytes)
+// TransactionReceipt looks up the receipt for txHash. A receipt whose block
+// is no longer canonical (a reorg in progress) is reported as pending.
+func (c *Client) TransactionReceipt(ctx context.Context, txHash string) (*Receipt, error) {
+	hash := common.HexToHash(txHash)
+
+	receipt, err := c.ethClient.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		_, isPending, err := c.ethClient.TransactionByHash(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrTxNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		return &Receipt{Pending: isPending}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	header, err := c.ethClient.HeaderByNumber(ctx, receipt.BlockNumber)
 	if err != nil {
-		return false, "", 0, fmt.Errorf("failed to pack call data: %w", err)
+		return nil, fmt.Errorf("failed to get block header: %w", err)
+	}
+	if header.Hash() != receipt.BlockHash {
+		return &Receipt{Pending: true}, nil
 	}
 
-	// Make static call
-	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
-		To:   &c.contractAddress,
-		Data: data,
-	}, nil)
+	head, err := c.ethClient.BlockNumber(ctx)
 	if err != nil {
-		return false, "", 0, fmt.Errorf("failed to call contract: %w", err)
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	result := &Receipt{
+		Reverted:    receipt.Status == types.ReceiptStatusFailed,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+		BlockHash:   receipt.BlockHash.Hex(),
+		BlockTime:   time.Unix(int64(header.Time), 0),
+		GasUsed:     receipt.GasUsed,
+	}
+	if head >= result.BlockNumber {
+		result.Confirmations = head - result.BlockNumber + 1
+	}
+	return result, nil
+}
+
+// ErrTxNotPending is returned when asked to replace a transaction that is already mined or unknown
+var ErrTxNotPending = errors.New("transaction is not pending")
+
+// ReplaceTransaction re-broadcasts a stuck pending transaction at the same
+// nonce with its fees raised by bumpPercent. Nodes reject replacements
+// bumped by less than 10%. It returns the hash of the replacement.
+func (c *Client) ReplaceTransaction(ctx context.Context, txHash string, bumpPercent int) (string, error) {
+	if bumpPercent < 10 {
+		bumpPercent = 10
 	}
 
-	// Unpack result
-	var (
-		isValid    bool
-		signerDID  common.Address
-		timestamp  *big.Int
-		hardwareID [32]byte
-	)
+	tx, isPending, err := c.ethClient.TransactionByHash(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) || (err == nil && !isPending) {
+		return "", ErrTxNotPending
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction: %w", err)
+	}
 
-	unpacked, err := c.contractABI.Unpack("verifySignature", result)
+	fees, err := c.bumpFees(tx, bumpPercent)
 	if err != nil {
-		return false, "", 0, fmt.Errorf("failed to unpack result: %w", err)
+		return "", err
 	}
 
-	isValid = unpacked[0].(bool)
-	signerDID = unpacked[1].(common.Address)
-	timestamp = unpacked[2].(*big.Int)
-	_ = hardwareID // Unused for now
+	signedTx, err := c.txSigner.SignTx(ctx, c.newTx(tx.Nonce(), tx.Gas(), fees, tx.Data()), c.chainID)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := c.ethClient.SendTransaction(ctx, signedTx); err != nil && !isAlreadyKnown(err) {
+		return "", fmt.Errorf("failed to send replacement: %w", err)
+	}
 
-	return isValid, signerDID.Hex(), timestamp.Int64(), nil
+	return signedTx.Hash().Hex(), nil
+}
+
+// Backend reports that the client anchors to a real node
+func (c *Client) Backend() string {
+	return BackendBesu
+}
+
+// ContractAddress returns the registry's address
+func (c *Client) ContractAddress() string {
+	return c.contractAddress.Hex()
 }
 
-// Close closes the Ethereum client connection
+// ChainStatus reads the head block, the node's sync progress and the signer's balance
+func (c *Client) ChainStatus(ctx context.Context) (*ChainStatus, error) {
+	header, err := c.ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head block: %w", err)
+	}
+	status := &ChainStatus{
+		ChainID:   c.chainID.Uint64(),
+		HeadBlock: header.Number.Uint64(),
+		HeadTime:  time.Unix(int64(header.Time), 0),
+		Signer:    c.from.Hex(),
+	}
+
+	progress, err := c.ethClient.SyncProgress(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync progress: %w", err)
+	}
+	if progress != nil && progress.HighestBlock > status.HeadBlock {
+		status.BlocksBehind = progress.HighestBlock - status.HeadBlock
+	}
+
+	if status.Balance, err = c.ethClient.BalanceAt(ctx, c.from, nil); err != nil {
+		return nil, fmt.Errorf("failed to get signer balance: %w", err)
+	}
+	return status, nil
+}
+
+// resyncNonces reloads the signer's nonce, e.g. after the node was unreachable
+func (c *Client) resyncNonces(ctx context.Context) error {
+	return c.nonces.Resync(ctx)
+}
+
+// Close closes the Ethereum client connection, and the remote signer's if there is one
 func (c *Client) Close() {
 	c.ethClient.Close()
+	if closer, ok := c.txSigner.(interface{ Close() }); ok {
+		closer.Close()
+	}
 }
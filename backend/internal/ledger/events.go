@@ -0,0 +1,190 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// InklessRegistry event names
+const (
+	EventSignatureAnchored = "SignatureAnchored"
+	EventSignerAnchored    = "SignerAnchored"
+	EventSignatureRevoked  = "SignatureRevoked"
+	EventSignerRevoked     = "SignerRevoked"
+	EventSignerVerified    = "SignerVerified"
+	EventSignerRemoved     = "SignerRemoved"
+)
+
+// Event is a decoded InklessRegistry log, flattened so every event kind
+// fits one shape
+type Event struct {
+	Name        string
+	DocHash     string // Empty for SignerVerified / SignerRemoved
+	SignerID    string // SignerAnchored / SignerRevoked only
+	Account     string // Anchoring account, revoker, or the verified/removed signer
+	HardwareID  string // Anchor events only
+	Timestamp   time.Time
+	BlockNumber uint64
+	BlockHash   string
+	TxHash      string
+	LogIndex    uint
+}
+
+// HeadBlock returns the current block number
+func (c *Client) HeadBlock(ctx context.Context) (uint64, error) {
+	head, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	return head, nil
+}
+
+// BlockHash returns the hash of the canonical block at number
+func (c *Client) BlockHash(ctx context.Context, number uint64) (string, error) {
+	header, err := c.ethClient.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return "", fmt.Errorf("failed to get block header: %w", err)
+	}
+	return header.Hash().Hex(), nil
+}
+
+// FilterEvents returns the contract's events in blocks from..to inclusive,
+// in chain order. Logs this binding doesn't know are skipped.
+func (c *Client) FilterEvents(ctx context.Context, from, to uint64) ([]Event, error) {
+	logs, err := c.ethClient.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{c.contractAddress},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs: %w", err)
+	}
+
+	events := make([]Event, 0, len(logs))
+	for _, l := range logs {
+		event, err := c.registry.DecodeEvent(l)
+		if err != nil {
+			return nil, err
+		}
+		if event != nil {
+			events = append(events, *event)
+		}
+	}
+	return events, nil
+}
+
+// SubscribeEvents signals notify whenever the contract emits a log. It only
+// works over a websocket or IPC endpoint; callers should still poll
+// FilterEvents, using the subscription to poll sooner.
+func (c *Client) SubscribeEvents(ctx context.Context, notify chan<- struct{}) (ethereum.Subscription, error) {
+	logs := make(chan types.Log)
+	sub, err := c.ethClient.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{c.contractAddress},
+	}, logs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to logs: %w", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-logs:
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// DecodeEvent decodes a contract log into an Event. It returns nil for a
+// log whose topic isn't one of the registry's events.
+func (r *Registry) DecodeEvent(l types.Log) (*Event, error) {
+	if len(l.Topics) == 0 {
+		return nil, nil
+	}
+	event := &Event{
+		BlockNumber: l.BlockNumber,
+		BlockHash:   l.BlockHash.Hex(),
+		TxHash:      l.TxHash.Hex(),
+		LogIndex:    l.Index,
+	}
+
+	switch l.Topics[0] {
+	case r.EventID(EventSignatureAnchored):
+		e, err := r.ParseSignatureAnchored(l)
+		if err != nil {
+			return nil, err
+		}
+		event.Name = EventSignatureAnchored
+		event.DocHash = common.Hash(e.DocHash).Hex()
+		event.Account = e.SignerDID.Hex()
+		event.HardwareID = common.Hash(e.HardwareID).Hex()
+		event.Timestamp = time.Unix(e.Timestamp.Int64(), 0)
+
+	case r.EventID(EventSignerAnchored):
+		e, err := r.ParseSignerAnchored(l)
+		if err != nil {
+			return nil, err
+		}
+		event.Name = EventSignerAnchored
+		event.DocHash = common.Hash(e.DocHash).Hex()
+		event.SignerID = common.Hash(e.SignerId).Hex()
+		event.HardwareID = common.Hash(e.HardwareID).Hex()
+		event.Timestamp = time.Unix(e.Timestamp.Int64(), 0)
+
+	case r.EventID(EventSignatureRevoked):
+		e, err := r.ParseSignatureRevoked(l)
+		if err != nil {
+			return nil, err
+		}
+		event.Name = EventSignatureRevoked
+		event.DocHash = common.Hash(e.DocHash).Hex()
+		event.Account = e.Revoker.Hex()
+		event.Timestamp = time.Unix(e.Timestamp.Int64(), 0)
+
+	case r.EventID(EventSignerRevoked):
+		e, err := r.ParseSignerRevoked(l)
+		if err != nil {
+			return nil, err
+		}
+		event.Name = EventSignerRevoked
+		event.DocHash = common.Hash(e.DocHash).Hex()
+		event.SignerID = common.Hash(e.SignerId).Hex()
+		event.Account = e.Revoker.Hex()
+		event.Timestamp = time.Unix(e.Timestamp.Int64(), 0)
+
+	case r.EventID(EventSignerVerified):
+		e, err := r.ParseSignerVerified(l)
+		if err != nil {
+			return nil, err
+		}
+		event.Name = EventSignerVerified
+		event.Account = e.SignerDID.Hex()
+		event.Timestamp = time.Unix(e.Timestamp.Int64(), 0)
+
+	case r.EventID(EventSignerRemoved):
+		e, err := r.ParseSignerRemoved(l)
+		if err != nil {
+			return nil, err
+		}
+		event.Name = EventSignerRemoved
+		event.Account = e.SignerDID.Hex()
+		event.Timestamp = time.Unix(e.Timestamp.Int64(), 0)
+
+	default:
+		return nil, nil
+	}
+
+	return event, nil
+}
@@ -0,0 +1,190 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// ErrFeeCapExceeded is returned when the network asks more than the configured caps allow
+	ErrFeeCapExceeded = errors.New("network fee exceeds configured cap")
+	// ErrWouldRevert is returned when gas estimation shows the call would revert
+	ErrWouldRevert = errors.New("transaction would revert")
+)
+
+// FeeConfig bounds what the signer wallet pays per transaction. Together the
+// caps limit the worst-case cost of one anchor to MaxGasLimit * MaxFeePerGas.
+type FeeConfig struct {
+	GasLimitMargin       int      // Percent added to EstimateGas
+	MaxGasLimit          uint64   // Hard ceiling on the gas limit
+	MaxFeePerGas         *big.Int // Wei; caps the EIP-1559 fee cap or the legacy gas price (nil = uncapped)
+	MaxPriorityFeePerGas *big.Int // Wei; caps the EIP-1559 tip (nil = uncapped)
+}
+
+// Fee defaults
+const (
+	DefaultGasLimitMargin = 20
+	DefaultMaxGasLimit    = 1_000_000
+)
+
+// withDefaults fills in unset limits
+func (f FeeConfig) withDefaults() FeeConfig {
+	if f.GasLimitMargin <= 0 {
+		f.GasLimitMargin = DefaultGasLimitMargin
+	}
+	if f.MaxGasLimit == 0 {
+		f.MaxGasLimit = DefaultMaxGasLimit
+	}
+	return f
+}
+
+// ParseGwei converts a decimal gwei amount such as "30" or "1.5" to wei.
+// An empty string means no cap.
+func ParseGwei(s string) (*big.Int, error) {
+	return parseUnits(s, params.GWei, "gwei")
+}
+
+// ParseEther converts a decimal ether amount such as "0.5" to wei. An empty
+// string means no threshold.
+func ParseEther(s string) (*big.Int, error) {
+	return parseUnits(s, params.Ether, "ether")
+}
+
+// parseUnits converts a positive decimal amount of unit (in wei) to wei
+func parseUnits(s string, unit float64, name string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	amount, ok := new(big.Float).SetString(s)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid %s amount %q", name, s)
+	}
+	wei, _ := new(big.Float).Mul(amount, big.NewFloat(unit)).Int(nil)
+	return wei, nil
+}
+
+// estimateGas returns EstimateGas plus the safety margin, bounded by MaxGasLimit
+func (c *Client) estimateGas(ctx context.Context, data []byte) (uint64, error) {
+	estimate, err := c.ethClient.EstimateGas(ctx, ethereum.CallMsg{
+		From: c.from,
+		To:   &c.contractAddress,
+		Data: data,
+	})
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "revert") {
+			return 0, fmt.Errorf("%w: %v", ErrWouldRevert, err)
+		}
+		return 0, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	gas := estimate * uint64(100+c.fees.GasLimitMargin) / 100
+	if gas > c.fees.MaxGasLimit {
+		return 0, fmt.Errorf("%w: gas limit %d above %d", ErrFeeCapExceeded, gas, c.fees.MaxGasLimit)
+	}
+	return gas, nil
+}
+
+// txFees are the fee fields of a transaction about to be built
+type txFees struct {
+	dynamic   bool
+	gasTipCap *big.Int // EIP-1559
+	gasFeeCap *big.Int // EIP-1559
+	gasPrice  *big.Int // Legacy
+}
+
+// suggestFees prices a transaction: type-2 with tip + 2x base fee on London
+// chains, SuggestGasPrice on chains whose head has no base fee
+func (c *Client) suggestFees(ctx context.Context) (*txFees, error) {
+	head, err := c.ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head block: %w", err)
+	}
+
+	if head.BaseFee == nil {
+		gasPrice, err := c.ethClient.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		if c.fees.MaxFeePerGas != nil && gasPrice.Cmp(c.fees.MaxFeePerGas) > 0 {
+			return nil, fmt.Errorf("%w: gas price %s wei above %s", ErrFeeCapExceeded, gasPrice, c.fees.MaxFeePerGas)
+		}
+		return &txFees{gasPrice: gasPrice}, nil
+	}
+
+	tip, err := c.ethClient.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+	if c.fees.MaxPriorityFeePerGas != nil && tip.Cmp(c.fees.MaxPriorityFeePerGas) > 0 {
+		tip = new(big.Int).Set(c.fees.MaxPriorityFeePerGas)
+	}
+
+	// Headroom for the base fee to double before the tx is priced out
+	feeCap := new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip)
+	if c.fees.MaxFeePerGas != nil && feeCap.Cmp(c.fees.MaxFeePerGas) > 0 {
+		if c.fees.MaxFeePerGas.Cmp(head.BaseFee) <= 0 {
+			return nil, fmt.Errorf("%w: base fee %s wei above %s", ErrFeeCapExceeded, head.BaseFee, c.fees.MaxFeePerGas)
+		}
+		feeCap = new(big.Int).Set(c.fees.MaxFeePerGas)
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+
+	return &txFees{dynamic: true, gasTipCap: tip, gasFeeCap: feeCap}, nil
+}
+
+// newTx builds an unsigned contract call at nonce
+func (c *Client) newTx(nonce, gas uint64, fees *txFees, data []byte) *types.Transaction {
+	if fees.dynamic {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   c.chainID,
+			Nonce:     nonce,
+			GasTipCap: fees.gasTipCap,
+			GasFeeCap: fees.gasFeeCap,
+			Gas:       gas,
+			To:        &c.contractAddress,
+			Value:     big.NewInt(0),
+			Data:      data,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: fees.gasPrice,
+		Gas:      gas,
+		To:       &c.contractAddress,
+		Value:    big.NewInt(0),
+		Data:     data,
+	})
+}
+
+// bumpFees raises a pending transaction's fees by percent for a same-nonce
+// replacement, refusing to go past the configured caps
+func (c *Client) bumpFees(tx *types.Transaction, percent int) (*txFees, error) {
+	bump := func(v *big.Int) *big.Int {
+		out := new(big.Int).Mul(v, big.NewInt(int64(100+percent)))
+		return out.Div(out, big.NewInt(100))
+	}
+
+	if tx.Type() == types.DynamicFeeTxType {
+		fees := &txFees{dynamic: true, gasTipCap: bump(tx.GasTipCap()), gasFeeCap: bump(tx.GasFeeCap())}
+		if c.fees.MaxFeePerGas != nil && fees.gasFeeCap.Cmp(c.fees.MaxFeePerGas) > 0 {
+			return nil, fmt.Errorf("%w: bumped fee cap %s wei above %s", ErrFeeCapExceeded, fees.gasFeeCap, c.fees.MaxFeePerGas)
+		}
+		return fees, nil
+	}
+
+	fees := &txFees{gasPrice: bump(tx.GasPrice())}
+	if c.fees.MaxFeePerGas != nil && fees.gasPrice.Cmp(c.fees.MaxFeePerGas) > 0 {
+		return nil, fmt.Errorf("%w: bumped gas price %s wei above %s", ErrFeeCapExceeded, fees.gasPrice, c.fees.MaxFeePerGas)
+	}
+	return fees, nil
+}
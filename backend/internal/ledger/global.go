@@ -1,43 +1,273 @@
 package ledger
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inkless/backend/internal/config"
 )
 
+// DefaultNetwork names the single network configured without LEDGER_NETWORKS
+const DefaultNetwork = "default"
+
+// NetworkConfig describes one named ledger network
+type NetworkConfig struct {
+	Name            string
+	Backend         string // besu or simulated
+	RPCURL          string
+	ChainID         uint64 // Chain the node must report; 0 accepts any
+	ContractAddress string
+	Signer          SignerConfig // Where the key that sends its transactions lives
+}
+
 var (
-	// Global is the global ledger client instance
-	Global *Client
-	once   sync.Once
+	// Global is the primary network's ledger; nil until it is connected.
+	// Check IsConnected before using it: Supervise may set it after startup.
+	Global Ledger
+
+	networksMu sync.RWMutex
+	configured []NetworkConfig       // In configuration order; the first is primary
+	networks   = map[string]Ledger{} // Connected networks by name
+
+	connected atomic.Bool
+	ready     = make(chan struct{})
 )
 
-// Initialize sets up the global ledger client
-func Initialize(rpcURL, contractAddr, privateKeyHex string) error {
-	var initErr error
+// ConfiguredNetworks reads the networks from cfg. Without LEDGER_NETWORKS
+// there is one, DefaultNetwork, from LEDGER_BACKEND, BESU_NODE_URL,
+// CONTRACT_ADDRESS and SIGNER_PRIVATE_KEY: a node when a contract is
+// configured, otherwise the simulated chain outside production, otherwise none.
+func ConfiguredNetworks(cfg *config.Config) ([]NetworkConfig, error) {
+	if len(cfg.LedgerNetworks) > 0 {
+		nets := make([]NetworkConfig, len(cfg.LedgerNetworks))
+		for i, n := range cfg.LedgerNetworks {
+			if n.ChainID < 0 {
+				return nil, fmt.Errorf("network %q has a negative chain ID", n.Name)
+			}
+			nets[i] = NetworkConfig{
+				Name:            n.Name,
+				Backend:         n.Backend,
+				RPCURL:          n.RPCURL,
+				ChainID:         uint64(n.ChainID),
+				ContractAddress: n.ContractAddress,
+				Signer: SignerConfig{
+					PrivateKey:           n.SignerPrivateKey,
+					KeystoreFile:         n.SignerKeystoreFile,
+					KeystorePasswordFile: n.SignerKeystorePasswordFile,
+					RemoteURL:            n.SignerRemoteURL,
+					RemoteAddress:        n.SignerRemoteAddress,
+					RemoteMethod:         n.SignerRemoteMethod,
+				},
+			}
+		}
+		return nets, nil
+	}
 
-	once.Do(func() {
-		// Skip initialization if contract address is not configured
-		if contractAddr == "" {
-			log.Println("[Ledger] No contract address configured, using mock mode")
-			return
+	n := NetworkConfig{
+		Name:            DefaultNetwork,
+		Backend:         cfg.LedgerBackend,
+		RPCURL:          cfg.BesuNodeURL,
+		ContractAddress: cfg.ContractAddress,
+		Signer: SignerConfig{
+			PrivateKey:           cfg.SignerPrivateKey,
+			KeystoreFile:         cfg.SignerKeystoreFile,
+			KeystorePasswordFile: cfg.SignerKeystorePasswordFile,
+			RemoteURL:            cfg.SignerRemoteURL,
+			RemoteAddress:        cfg.SignerRemoteAddress,
+			RemoteMethod:         cfg.SignerRemoteMethod,
+		},
+	}
+	switch {
+	case n.Backend == BackendBesu || n.Backend == BackendSimulated:
+	case n.Backend != "":
+		return nil, fmt.Errorf("invalid LEDGER_BACKEND %q: use besu or simulated", n.Backend)
+	case n.ContractAddress != "":
+		n.Backend = BackendBesu
+	case cfg.Environment != "production":
+		n.Backend = BackendSimulated
+	default:
+		return nil, nil
+	}
+	return []NetworkConfig{n}, nil
+}
+
+// Configure declares the networks signatures can be anchored on. The first
+// is the primary network: Global, the indexer and reconciliation use it.
+func Configure(nets []NetworkConfig) error {
+	seen := make(map[string]bool, len(nets))
+	for _, n := range nets {
+		if n.Name == "" {
+			return fmt.Errorf("network has no name")
+		}
+		if seen[n.Name] {
+			return fmt.Errorf("network %q is configured twice", n.Name)
 		}
+		seen[n.Name] = true
+		switch n.Backend {
+		case BackendSimulated:
+		case BackendBesu:
+			if n.ContractAddress == "" {
+				return fmt.Errorf("network %q has no contract address", n.Name)
+			}
+		default:
+			return fmt.Errorf("network %q has unknown backend %q: use besu or simulated", n.Name, n.Backend)
+		}
+	}
+
+	networksMu.Lock()
+	configured = append([]NetworkConfig(nil), nets...)
+	networksMu.Unlock()
 
-		client, err := NewClient(rpcURL, contractAddr, privateKeyHex)
+	healthMu.Lock()
+	healths = make(map[string]Health, len(nets))
+	for _, n := range nets {
+		healths[n.Name] = Health{Name: n.Name, Backend: n.Backend, Network: networkName(n.Backend, n.ChainID), ChainID: n.ChainID, Status: HealthDown}
+	}
+	healthMu.Unlock()
+	return nil
+}
+
+// Networks returns the configured networks, primary first
+func Networks() []NetworkConfig {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	return append([]NetworkConfig(nil), configured...)
+}
+
+// PrimaryNetwork names the primary network, or "" if none is configured
+func PrimaryNetwork() string {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	if len(configured) == 0 {
+		return ""
+	}
+	return configured[0].Name
+}
+
+// IsNetwork reports whether name is a configured network
+func IsNetwork(name string) bool {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	for _, n := range configured {
+		if n.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Network returns the named network's ledger, or nil until it is connected.
+// The empty name is the primary network, for jobs queued before networks
+// were named.
+func Network(name string) Ledger {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	if name == "" && len(configured) > 0 {
+		name = configured[0].Name
+	}
+	return networks[name]
+}
+
+// ConnectedNetworks names the networks that are connected
+func ConnectedNetworks() []string {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	var names []string
+	for _, n := range configured {
+		if networks[n.Name] != nil {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+// connectTimeout bounds reaching a remote signer in Connect
+const connectTimeout = 30 * time.Second
+
+// Connect dials the network's node, or starts a simulated chain, and
+// publishes it. Connecting a network that already is does nothing. Long-
+// running processes use Supervise instead, which keeps retrying.
+func Connect(n NetworkConfig, fees FeeConfig) error {
+	if Network(n.Name) != nil {
+		return nil
+	}
+
+	var l Ledger
+	if n.Backend == BackendSimulated {
+		l = NewSimulated()
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+		txSigner, err := NewTxSigner(ctx, n.Signer)
+		cancel()
 		if err != nil {
-			log.Printf("[Ledger] Failed to initialize: %v (falling back to mock mode)", err)
-			initErr = err
-			return
+			return fmt.Errorf("transaction signer: %w", err)
 		}
+		client, err := NewClient(n.RPCURL, n.ContractAddress, txSigner, fees)
+		if err != nil {
+			if closer, ok := txSigner.(interface{ Close() }); ok {
+				closer.Close()
+			}
+			return err
+		}
+		if n.ChainID != 0 && client.chainID.Uint64() != n.ChainID {
+			client.Close()
+			return fmt.Errorf("node at %s is on chain %d, expected %d", n.RPCURL, client.chainID.Uint64(), n.ChainID)
+		}
+		l = client
+	}
 
-		Global = client
-		log.Printf("[Ledger] Connected to blockchain at %s", rpcURL)
-		log.Printf("[Ledger] Contract: %s", contractAddr)
-	})
+	if !publish(n.Name, l) {
+		if client, ok := l.(*Client); ok {
+			client.Close() // Connected concurrently
+		}
+		return nil
+	}
+	if client, ok := l.(*Client); ok {
+		log.Printf("[Ledger] Network %s connected to blockchain at %s", n.Name, n.RPCURL)
+		log.Printf("[Ledger] Network %s contract: %s", n.Name, n.ContractAddress)
+		log.Printf("[Ledger] Network %s sends from %s (%s signer)", n.Name, client.from.Hex(), n.Signer.Kind())
+	} else {
+		log.Printf("[Ledger] Network %s uses the simulated in-memory chain; anchors are not on any real ledger", n.Name)
+	}
+	return nil
+}
 
-	return initErr
+// publish records l as the named network's ledger, and as Global for the
+// primary network. Global is written before the flag, so any caller that saw
+// IsConnected also sees Global. It reports false if the network already had one.
+func publish(name string, l Ledger) bool {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+	if networks[name] != nil {
+		return false
+	}
+	networks[name] = l
+	if len(configured) > 0 && configured[0].Name == name {
+		Global = l
+		connected.Store(true)
+		close(ready)
+	}
+	return true
 }
 
-// IsConnected returns true if the ledger client is connected
+// IsConnected returns true once the primary network, real or simulated, is connected
 func IsConnected() bool {
-	return Global != nil
+	return connected.Load()
+}
+
+// Ready is closed once the primary network is connected
+func Ready() <-chan struct{} {
+	return ready
+}
+
+// BackendName names the primary network's backend, or "none"
+func BackendName() string {
+	if !IsConnected() {
+		return "none"
+	}
+	return Global.Backend()
 }
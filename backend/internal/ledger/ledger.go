@@ -0,0 +1,60 @@
+package ledger
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// Ledger backends, recorded on every signature they anchor
+const (
+	BackendBesu      = "besu"      // An EVM node running InklessRegistry
+	BackendSimulated = "simulated" // The in-memory chain used in development
+	BackendMock      = "mock"      // Rows given a fabricated hash before the simulated chain existed
+)
+
+var (
+	_ Ledger = (*Client)(nil)
+	_ Ledger = (*Simulated)(nil)
+)
+
+// ChainStatus is a snapshot of the ledger as its node sees it
+type ChainStatus struct {
+	ChainID      uint64
+	HeadBlock    uint64
+	HeadTime     time.Time
+	BlocksBehind uint64   // While the node syncs: highest known block minus HeadBlock
+	Signer       string   // Account anchors are sent from
+	Balance      *big.Int // Signer balance in wei; nil where there is none
+}
+
+// Ledger is the chain the backend anchors to. Client talks to a real node;
+// Simulated keeps an in-memory chain for development and demos.
+type Ledger interface {
+	// Backend names the implementation, e.g. BackendBesu
+	Backend() string
+	// ContractAddress is the registry the ledger reads and writes
+	ContractAddress() string
+	// ChainStatus reports the node's head, sync progress and signer balance
+	ChainStatus(ctx context.Context) (*ChainStatus, error)
+
+	AnchorSignature(ctx context.Context, docHash, signerDID string, signature []byte, hardwareID string) (string, error)
+	AnchorBatchRoot(ctx context.Context, root string, descriptor []byte) (string, error)
+	RevokeSignature(ctx context.Context, docHash, signerDID string, legacy bool) (string, error)
+	SetVerifiedSigner(ctx context.Context, address string, verified bool) (string, error)
+
+	VerifySignature(ctx context.Context, docHash string, candidateDIDs []string) ([]SignerRecord, error)
+	IsVerifiedSigner(ctx context.Context, address string) (bool, error)
+	SignerRecordOf(ctx context.Context, docHash, signerDID string) (*SignerRecord, error)
+	BatchRootRecord(ctx context.Context, root string) (*SignerRecord, error)
+
+	TransactionReceipt(ctx context.Context, txHash string) (*Receipt, error)
+	ReplaceTransaction(ctx context.Context, txHash string, bumpPercent int) (string, error)
+
+	HeadBlock(ctx context.Context) (uint64, error)
+	BlockHash(ctx context.Context, number uint64) (string, error)
+	FilterEvents(ctx context.Context, from, to uint64) ([]Event, error)
+	SubscribeEvents(ctx context.Context, notify chan<- struct{}) (ethereum.Subscription, error)
+}
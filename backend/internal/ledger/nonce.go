@@ -0,0 +1,112 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxNonceRetries bounds resync-and-retry rounds for one submission
+const maxNonceRetries = 3
+
+// nonceBackend is the part of the node API the nonce manager needs
+type nonceBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// NonceManager hands out nonces for one sending account. Submissions are
+// serialised so a nonce is only consumed once the node accepts the tx: a
+// failed send never leaves a gap that would stall every later transaction.
+type NonceManager struct {
+	mu      sync.Mutex
+	backend nonceBackend
+	account common.Address
+	next    uint64
+	synced  bool
+}
+
+// NewNonceManager creates a manager for account. It syncs from the node on first use.
+func NewNonceManager(backend nonceBackend, account common.Address) *NonceManager {
+	return &NonceManager{backend: backend, account: account}
+}
+
+// Resync reloads the next nonce from the node's pending state. Called on
+// startup so transactions still in the pool from a previous run are skipped.
+func (m *NonceManager) Resync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.resyncLocked(ctx)
+}
+
+func (m *NonceManager) resyncLocked(ctx context.Context) error {
+	nonce, err := m.backend.PendingNonceAt(ctx, m.account)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	m.next = nonce
+	m.synced = true
+	return nil
+}
+
+// Send builds a transaction at the next nonce and submits it. If the node
+// reports the nonce as used ("nonce too low", "replacement transaction
+// underpriced") the manager resyncs and builds again. "already known" means
+// this very tx is in the pool, e.g. from a send whose response was lost, so
+// it counts as sent: building it again at a new nonce would send it twice.
+func (m *NonceManager) Send(ctx context.Context, build func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.synced {
+		if err := m.resyncLocked(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxNonceRetries; attempt++ {
+		tx, err := build(m.next)
+		if err != nil {
+			return nil, err
+		}
+
+		lastErr = m.backend.SendTransaction(ctx, tx)
+		if lastErr == nil || isAlreadyKnown(lastErr) {
+			m.next++
+			return tx, nil
+		}
+		if !isNonceConflict(lastErr) {
+			return nil, fmt.Errorf("failed to send transaction: %w", lastErr)
+		}
+
+		// Someone else holds this nonce; take the node's view, but always move
+		// forward in case the node we talk to lags the pool that rejected us
+		used := m.next
+		if err := m.resyncLocked(ctx); err != nil {
+			return nil, err
+		}
+		if m.next <= used {
+			m.next = used + 1
+		}
+	}
+
+	return nil, fmt.Errorf("failed to send transaction after %d nonce retries: %w", maxNonceRetries, lastErr)
+}
+
+// isNonceConflict reports node errors meaning the nonce is taken by another tx
+func isNonceConflict(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "replacement transaction underpriced")
+}
+
+// isAlreadyKnown reports the node error for a tx identical to one in its pool
+func isAlreadyKnown(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
@@ -0,0 +1,77 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeNonceBackend answers sends with errs in turn, then accepts
+type fakeNonceBackend struct {
+	pending uint64
+	errs    []error
+	sent    []*types.Transaction
+}
+
+func (b *fakeNonceBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return b.pending, nil
+}
+
+func (b *fakeNonceBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	if len(b.errs) == 0 {
+		return nil
+	}
+	err := b.errs[0]
+	b.errs = b.errs[1:]
+	return err
+}
+
+func buildAt(nonce uint64) (*types.Transaction, error) {
+	return types.NewTx(&types.LegacyTx{Nonce: nonce, Gas: 21000, GasPrice: big.NewInt(1)}), nil
+}
+
+func TestNonceManagerSend(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantNonce uint64 // Nonce of the tx Send returns
+		wantSends int
+		wantNext  uint64
+		wantErr   bool
+	}{
+		{name: "accepted", wantNonce: 5, wantSends: 1, wantNext: 6},
+		{name: "already known is sent", errs: []error{errors.New("already known")}, wantNonce: 5, wantSends: 1, wantNext: 6},
+		{name: "nonce too low moves on", errs: []error{errors.New("nonce too low")}, wantNonce: 6, wantSends: 2, wantNext: 7},
+		{name: "underpriced replacement moves on", errs: []error{errors.New("replacement transaction underpriced")}, wantNonce: 6, wantSends: 2, wantNext: 7},
+		{name: "other errors are returned", errs: []error{errors.New("insufficient funds")}, wantSends: 1, wantNext: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeNonceBackend{pending: 5, errs: tt.errs}
+			m := NewNonceManager(backend, common.Address{})
+
+			tx, err := m.Send(context.Background(), buildAt)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Send succeeded, want an error")
+				}
+			} else if err != nil {
+				t.Fatalf("Send: %v", err)
+			} else if tx.Nonce() != tt.wantNonce {
+				t.Errorf("sent at nonce %d, want %d", tx.Nonce(), tt.wantNonce)
+			}
+			if len(backend.sent) != tt.wantSends {
+				t.Errorf("%d sends, want %d", len(backend.sent), tt.wantSends)
+			}
+			if m.next != tt.wantNext {
+				t.Errorf("next nonce %d, want %d", m.next, tt.wantNext)
+			}
+		})
+	}
+}
@@ -0,0 +1,674 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// InklessRegistryABI is the full ABI of contracts/InklessRegistry.sol
+const InklessRegistryABI = `[
+	{"inputs": [], "stateMutability": "nonpayable", "type": "constructor"},
+	{
+		"inputs": [
+			{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"},
+			{"internalType": "bytes", "name": "_pqcSignature", "type": "bytes"},
+			{"internalType": "bytes32", "name": "_hardwareID", "type": "bytes32"}
+		],
+		"name": "anchorSignature",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"},
+			{"internalType": "bytes32", "name": "_signerId", "type": "bytes32"},
+			{"internalType": "bytes", "name": "_pqcSignature", "type": "bytes"},
+			{"internalType": "bytes32", "name": "_hardwareID", "type": "bytes32"}
+		],
+		"name": "anchorSignerSignature",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"},
+			{"internalType": "bytes32", "name": "_signerId", "type": "bytes32"},
+			{"internalType": "bytes", "name": "_pqcSignature", "type": "bytes"},
+			{"internalType": "bytes32", "name": "_hardwareID", "type": "bytes32"},
+			{"internalType": "address", "name": "_account", "type": "address"},
+			{"internalType": "bytes", "name": "_accountSignature", "type": "bytes"}
+		],
+		"name": "anchorSignerSignatureFor",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"}],
+		"name": "revokeSignature",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"},
+			{"internalType": "bytes32", "name": "_signerId", "type": "bytes32"}
+		],
+		"name": "revokeSignerSignature",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "_signer", "type": "address"}],
+		"name": "addVerifiedSigner",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "_signer", "type": "address"}],
+		"name": "removeVerifiedSigner",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "_newOwner", "type": "address"}],
+		"name": "transferOwnership",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"}],
+		"name": "verifySignature",
+		"outputs": [
+			{"internalType": "bool", "name": "isValid", "type": "bool"},
+			{"internalType": "address", "name": "signerDID", "type": "address"},
+			{"internalType": "uint256", "name": "timestamp", "type": "uint256"},
+			{"internalType": "bytes32", "name": "hardwareID", "type": "bytes32"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"}],
+		"name": "getSignatureRecord",
+		"outputs": [
+			{
+				"components": [
+					{"internalType": "bytes32", "name": "docHash", "type": "bytes32"},
+					{"internalType": "address", "name": "signerDID", "type": "address"},
+					{"internalType": "bytes", "name": "pqcSignature", "type": "bytes"},
+					{"internalType": "uint256", "name": "timestamp", "type": "uint256"},
+					{"internalType": "bytes32", "name": "hardwareID", "type": "bytes32"},
+					{"internalType": "bool", "name": "isRevoked", "type": "bool"}
+				],
+				"internalType": "struct InklessRegistry.SignatureRecord",
+				"name": "",
+				"type": "tuple"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"}],
+		"name": "getDocumentSigners",
+		"outputs": [
+			{
+				"components": [
+					{"internalType": "bytes32", "name": "signerId", "type": "bytes32"},
+					{"internalType": "address", "name": "submitter", "type": "address"},
+					{"internalType": "bytes", "name": "pqcSignature", "type": "bytes"},
+					{"internalType": "uint256", "name": "timestamp", "type": "uint256"},
+					{"internalType": "bytes32", "name": "hardwareID", "type": "bytes32"},
+					{"internalType": "bool", "name": "isRevoked", "type": "bool"}
+				],
+				"internalType": "struct InklessRegistry.SignerRecord[]",
+				"name": "records",
+				"type": "tuple[]"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "getTotalDocuments",
+		"outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "_signer", "type": "address"}],
+		"name": "isVerifiedSigner",
+		"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "bytes32", "name": "_docHash", "type": "bytes32"}],
+		"name": "isDocumentAnchored",
+		"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "bytes32", "name": "", "type": "bytes32"}],
+		"name": "signatures",
+		"outputs": [
+			{"internalType": "bytes32", "name": "docHash", "type": "bytes32"},
+			{"internalType": "address", "name": "signerDID", "type": "address"},
+			{"internalType": "bytes", "name": "pqcSignature", "type": "bytes"},
+			{"internalType": "uint256", "name": "timestamp", "type": "uint256"},
+			{"internalType": "bytes32", "name": "hardwareID", "type": "bytes32"},
+			{"internalType": "bool", "name": "isRevoked", "type": "bool"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+		"name": "documentHashes",
+		"outputs": [{"internalType": "bytes32", "name": "", "type": "bytes32"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "bytes32", "name": "", "type": "bytes32"},
+			{"internalType": "bytes32", "name": "", "type": "bytes32"}
+		],
+		"name": "signerRecords",
+		"outputs": [
+			{"internalType": "bytes32", "name": "signerId", "type": "bytes32"},
+			{"internalType": "address", "name": "submitter", "type": "address"},
+			{"internalType": "bytes", "name": "pqcSignature", "type": "bytes"},
+			{"internalType": "uint256", "name": "timestamp", "type": "uint256"},
+			{"internalType": "bytes32", "name": "hardwareID", "type": "bytes32"},
+			{"internalType": "bool", "name": "isRevoked", "type": "bool"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "bytes32", "name": "", "type": "bytes32"},
+			{"internalType": "bytes32", "name": "", "type": "bytes32"}
+		],
+		"name": "signerAccounts",
+		"outputs": [{"internalType": "address", "name": "", "type": "address"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "DOMAIN_SEPARATOR",
+		"outputs": [{"internalType": "bytes32", "name": "", "type": "bytes32"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "", "type": "address"}],
+		"name": "verifiedSigners",
+		"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "owner",
+		"outputs": [{"internalType": "address", "name": "", "type": "address"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "bytes32", "name": "docHash", "type": "bytes32"},
+			{"indexed": true, "internalType": "address", "name": "signerDID", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"},
+			{"indexed": false, "internalType": "bytes32", "name": "hardwareID", "type": "bytes32"}
+		],
+		"name": "SignatureAnchored",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "bytes32", "name": "docHash", "type": "bytes32"},
+			{"indexed": true, "internalType": "bytes32", "name": "signerId", "type": "bytes32"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"},
+			{"indexed": false, "internalType": "bytes32", "name": "hardwareID", "type": "bytes32"}
+		],
+		"name": "SignerAnchored",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "bytes32", "name": "docHash", "type": "bytes32"},
+			{"indexed": true, "internalType": "address", "name": "revoker", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
+		"name": "SignatureRevoked",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "bytes32", "name": "docHash", "type": "bytes32"},
+			{"indexed": true, "internalType": "bytes32", "name": "signerId", "type": "bytes32"},
+			{"indexed": true, "internalType": "address", "name": "revoker", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
+		"name": "SignerRevoked",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "signerDID", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
+		"name": "SignerVerified",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "signerDID", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
+		"name": "SignerRemoved",
+		"type": "event"
+	}
+]`
+
+// RegistrySignatureRecord mirrors InklessRegistry.SignatureRecord, the
+// document-keyed record written by anchorSignature
+type RegistrySignatureRecord struct {
+	DocHash      [32]byte
+	SignerDID    common.Address // Account that anchored it
+	PqcSignature []byte
+	Timestamp    *big.Int
+	HardwareID   [32]byte
+	IsRevoked    bool
+}
+
+// RegistrySignerRecord mirrors InklessRegistry.SignerRecord, one signer's
+// record written by anchorSignerSignature
+type RegistrySignerRecord struct {
+	SignerId     [32]byte
+	Submitter    common.Address
+	PqcSignature []byte
+	Timestamp    *big.Int
+	HardwareID   [32]byte
+	IsRevoked    bool
+}
+
+// RegistryVerification is the result of verifySignature
+type RegistryVerification struct {
+	IsValid    bool
+	SignerDID  common.Address
+	Timestamp  *big.Int
+	HardwareID [32]byte
+}
+
+// Registry is a typed binding to a deployed InklessRegistry. View functions
+// are called directly; state-changing functions only pack calldata, which
+// the Client signs and sends under its nonce manager.
+type Registry struct {
+	abi     abi.ABI
+	address common.Address
+	caller  ethereum.ContractCaller
+}
+
+// NewRegistry binds the contract at address
+func NewRegistry(address common.Address, caller ethereum.ContractCaller) (*Registry, error) {
+	parsed, err := abi.JSON(strings.NewReader(InklessRegistryABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	return &Registry{abi: parsed, address: address, caller: caller}, nil
+}
+
+// Address returns the bound contract address
+func (r *Registry) Address() common.Address {
+	return r.address
+}
+
+// ABI returns the parsed contract ABI
+func (r *Registry) ABI() abi.ABI {
+	return r.abi
+}
+
+// ============ View Functions ============
+
+// VerifySignature calls verifySignature(docHash)
+func (r *Registry) VerifySignature(ctx context.Context, docHash [32]byte) (*RegistryVerification, error) {
+	out := new(RegistryVerification)
+	if err := r.call(ctx, out, "verifySignature", docHash); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetSignatureRecord calls getSignatureRecord(docHash). The contract reverts
+// for a document that was never anchored under its own hash.
+func (r *Registry) GetSignatureRecord(ctx context.Context, docHash [32]byte) (*RegistrySignatureRecord, error) {
+	var out struct{ Record RegistrySignatureRecord }
+	if err := r.call(ctx, &out, "getSignatureRecord", docHash); err != nil {
+		return nil, err
+	}
+	return &out.Record, nil
+}
+
+// GetDocumentSigners calls getDocumentSigners(docHash)
+func (r *Registry) GetDocumentSigners(ctx context.Context, docHash [32]byte) ([]RegistrySignerRecord, error) {
+	var out struct{ Records []RegistrySignerRecord }
+	if err := r.call(ctx, &out, "getDocumentSigners", docHash); err != nil {
+		return nil, err
+	}
+	return out.Records, nil
+}
+
+// GetTotalDocuments calls getTotalDocuments()
+func (r *Registry) GetTotalDocuments(ctx context.Context) (*big.Int, error) {
+	var out struct{ Total *big.Int }
+	if err := r.call(ctx, &out, "getTotalDocuments"); err != nil {
+		return nil, err
+	}
+	return out.Total, nil
+}
+
+// IsVerifiedSigner calls isVerifiedSigner(signer)
+func (r *Registry) IsVerifiedSigner(ctx context.Context, signer common.Address) (bool, error) {
+	var out struct{ Verified bool }
+	if err := r.call(ctx, &out, "isVerifiedSigner", signer); err != nil {
+		return false, err
+	}
+	return out.Verified, nil
+}
+
+// IsDocumentAnchored calls isDocumentAnchored(docHash)
+func (r *Registry) IsDocumentAnchored(ctx context.Context, docHash [32]byte) (bool, error) {
+	var out struct{ Anchored bool }
+	if err := r.call(ctx, &out, "isDocumentAnchored", docHash); err != nil {
+		return false, err
+	}
+	return out.Anchored, nil
+}
+
+// Signatures reads the public signatures mapping. Unlike GetSignatureRecord
+// it returns a zero record instead of reverting for unknown documents.
+func (r *Registry) Signatures(ctx context.Context, docHash [32]byte) (*RegistrySignatureRecord, error) {
+	out := new(RegistrySignatureRecord)
+	if err := r.call(ctx, out, "signatures", docHash); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DocumentHashes reads the public documentHashes array
+func (r *Registry) DocumentHashes(ctx context.Context, index *big.Int) ([32]byte, error) {
+	var out struct{ DocHash [32]byte }
+	if err := r.call(ctx, &out, "documentHashes", index); err != nil {
+		return [32]byte{}, err
+	}
+	return out.DocHash, nil
+}
+
+// SignerRecords reads the public signerRecords mapping
+func (r *Registry) SignerRecords(ctx context.Context, docHash, signerID [32]byte) (*RegistrySignerRecord, error) {
+	out := new(RegistrySignerRecord)
+	if err := r.call(ctx, out, "signerRecords", docHash, signerID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SignerAccounts reads the public signerAccounts mapping: the account that
+// approved a signer's relayed anchor, or the zero address
+func (r *Registry) SignerAccounts(ctx context.Context, docHash, signerID [32]byte) (common.Address, error) {
+	var out struct{ Account common.Address }
+	if err := r.call(ctx, &out, "signerAccounts", docHash, signerID); err != nil {
+		return common.Address{}, err
+	}
+	return out.Account, nil
+}
+
+// DomainSeparator reads the contract's EIP-712 domain separator
+func (r *Registry) DomainSeparator(ctx context.Context) ([32]byte, error) {
+	var out struct{ Separator [32]byte }
+	if err := r.call(ctx, &out, "DOMAIN_SEPARATOR"); err != nil {
+		return [32]byte{}, err
+	}
+	return out.Separator, nil
+}
+
+// VerifiedSigners reads the public verifiedSigners mapping
+func (r *Registry) VerifiedSigners(ctx context.Context, signer common.Address) (bool, error) {
+	var out struct{ Verified bool }
+	if err := r.call(ctx, &out, "verifiedSigners", signer); err != nil {
+		return false, err
+	}
+	return out.Verified, nil
+}
+
+// Owner reads the contract owner
+func (r *Registry) Owner(ctx context.Context) (common.Address, error) {
+	var out struct{ Owner common.Address }
+	if err := r.call(ctx, &out, "owner"); err != nil {
+		return common.Address{}, err
+	}
+	return out.Owner, nil
+}
+
+// ============ Transactions ============
+
+// PackAnchorSignature packs anchorSignature(docHash, pqcSignature, hardwareID)
+func (r *Registry) PackAnchorSignature(docHash [32]byte, pqcSignature []byte, hardwareID [32]byte) ([]byte, error) {
+	return r.pack("anchorSignature", docHash, pqcSignature, hardwareID)
+}
+
+// PackAnchorSignerSignature packs anchorSignerSignature(docHash, signerId, pqcSignature, hardwareID)
+func (r *Registry) PackAnchorSignerSignature(docHash, signerID [32]byte, pqcSignature []byte, hardwareID [32]byte) ([]byte, error) {
+	return r.pack("anchorSignerSignature", docHash, signerID, pqcSignature, hardwareID)
+}
+
+// PackAnchorSignerSignatureFor packs anchorSignerSignatureFor(docHash,
+// signerId, pqcSignature, hardwareID, account, accountSignature)
+func (r *Registry) PackAnchorSignerSignatureFor(docHash, signerID [32]byte, pqcSignature []byte, hardwareID [32]byte, account common.Address, accountSignature []byte) ([]byte, error) {
+	return r.pack("anchorSignerSignatureFor", docHash, signerID, pqcSignature, hardwareID, account, accountSignature)
+}
+
+// PackRevokeSignature packs revokeSignature(docHash)
+func (r *Registry) PackRevokeSignature(docHash [32]byte) ([]byte, error) {
+	return r.pack("revokeSignature", docHash)
+}
+
+// PackRevokeSignerSignature packs revokeSignerSignature(docHash, signerId)
+func (r *Registry) PackRevokeSignerSignature(docHash, signerID [32]byte) ([]byte, error) {
+	return r.pack("revokeSignerSignature", docHash, signerID)
+}
+
+// PackAddVerifiedSigner packs addVerifiedSigner(signer)
+func (r *Registry) PackAddVerifiedSigner(signer common.Address) ([]byte, error) {
+	return r.pack("addVerifiedSigner", signer)
+}
+
+// PackRemoveVerifiedSigner packs removeVerifiedSigner(signer)
+func (r *Registry) PackRemoveVerifiedSigner(signer common.Address) ([]byte, error) {
+	return r.pack("removeVerifiedSigner", signer)
+}
+
+// PackTransferOwnership packs transferOwnership(newOwner)
+func (r *Registry) PackTransferOwnership(newOwner common.Address) ([]byte, error) {
+	return r.pack("transferOwnership", newOwner)
+}
+
+// ============ Events ============
+
+// RegistrySignatureAnchored is a SignatureAnchored event
+type RegistrySignatureAnchored struct {
+	DocHash    [32]byte
+	SignerDID  common.Address
+	Timestamp  *big.Int
+	HardwareID [32]byte
+	Raw        types.Log
+}
+
+// RegistrySignerAnchored is a SignerAnchored event
+type RegistrySignerAnchored struct {
+	DocHash    [32]byte
+	SignerId   [32]byte
+	Timestamp  *big.Int
+	HardwareID [32]byte
+	Raw        types.Log
+}
+
+// RegistrySignatureRevoked is a SignatureRevoked event
+type RegistrySignatureRevoked struct {
+	DocHash   [32]byte
+	Revoker   common.Address
+	Timestamp *big.Int
+	Raw       types.Log
+}
+
+// RegistrySignerRevoked is a SignerRevoked event
+type RegistrySignerRevoked struct {
+	DocHash   [32]byte
+	SignerId  [32]byte
+	Revoker   common.Address
+	Timestamp *big.Int
+	Raw       types.Log
+}
+
+// RegistrySignerVerified is a SignerVerified event
+type RegistrySignerVerified struct {
+	SignerDID common.Address
+	Timestamp *big.Int
+	Raw       types.Log
+}
+
+// RegistrySignerRemoved is a SignerRemoved event
+type RegistrySignerRemoved struct {
+	SignerDID common.Address
+	Timestamp *big.Int
+	Raw       types.Log
+}
+
+// EventID returns the topic hash of the named event
+func (r *Registry) EventID(name string) common.Hash {
+	return r.abi.Events[name].ID
+}
+
+// ParseSignatureAnchored decodes a SignatureAnchored log
+func (r *Registry) ParseSignatureAnchored(log types.Log) (*RegistrySignatureAnchored, error) {
+	event := &RegistrySignatureAnchored{Raw: log}
+	return event, r.unpackLog(event, "SignatureAnchored", log)
+}
+
+// ParseSignerAnchored decodes a SignerAnchored log
+func (r *Registry) ParseSignerAnchored(log types.Log) (*RegistrySignerAnchored, error) {
+	event := &RegistrySignerAnchored{Raw: log}
+	return event, r.unpackLog(event, "SignerAnchored", log)
+}
+
+// ParseSignatureRevoked decodes a SignatureRevoked log
+func (r *Registry) ParseSignatureRevoked(log types.Log) (*RegistrySignatureRevoked, error) {
+	event := &RegistrySignatureRevoked{Raw: log}
+	return event, r.unpackLog(event, "SignatureRevoked", log)
+}
+
+// ParseSignerRevoked decodes a SignerRevoked log
+func (r *Registry) ParseSignerRevoked(log types.Log) (*RegistrySignerRevoked, error) {
+	event := &RegistrySignerRevoked{Raw: log}
+	return event, r.unpackLog(event, "SignerRevoked", log)
+}
+
+// ParseSignerVerified decodes a SignerVerified log
+func (r *Registry) ParseSignerVerified(log types.Log) (*RegistrySignerVerified, error) {
+	event := &RegistrySignerVerified{Raw: log}
+	return event, r.unpackLog(event, "SignerVerified", log)
+}
+
+// ParseSignerRemoved decodes a SignerRemoved log
+func (r *Registry) ParseSignerRemoved(log types.Log) (*RegistrySignerRemoved, error) {
+	event := &RegistrySignerRemoved{Raw: log}
+	return event, r.unpackLog(event, "SignerRemoved", log)
+}
+
+// ============ Helpers ============
+
+// call makes a static call to a view function and decodes its outputs into out
+// errUndecodable is wrapped by call when the contract answers with output
+// the method can't have returned, as it does when it lacks the method
+var errUndecodable = errors.New("undecodable call output")
+
+func (r *Registry) call(ctx context.Context, out interface{}, method string, args ...interface{}) error {
+	data, err := r.pack(method, args...)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &r.address, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	unpacked, err := r.abi.Unpack(method, result)
+	if err != nil {
+		return fmt.Errorf("failed to unpack %s: %w: %w", method, errUndecodable, err)
+	}
+	if err := r.abi.Methods[method].Outputs.Copy(out, unpacked); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", method, err)
+	}
+	return nil
+}
+
+// pack encodes calldata for method
+func (r *Registry) pack(method string, args ...interface{}) ([]byte, error) {
+	data, err := r.abi.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+	return data, nil
+}
+
+// unpackLog decodes the indexed topics and data of an event log into out
+func (r *Registry) unpackLog(out interface{}, event string, log types.Log) error {
+	if len(log.Topics) == 0 || log.Topics[0] != r.abi.Events[event].ID {
+		return fmt.Errorf("log is not a %s event", event)
+	}
+	if len(log.Data) > 0 {
+		if err := r.abi.UnpackIntoInterface(out, event, log.Data); err != nil {
+			return fmt.Errorf("failed to unpack %s: %w", event, err)
+		}
+	}
+
+	var indexed abi.Arguments
+	for _, arg := range r.abi.Events[event].Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopics(out, indexed, log.Topics[1:]); err != nil {
+		return fmt.Errorf("failed to parse %s topics: %w", event, err)
+	}
+	return nil
+}
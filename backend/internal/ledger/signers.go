@@ -0,0 +1,196 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignerRecord is one signer's anchor as stored on-chain
+type SignerRecord struct {
+	SignerID   string // keccak256 of the DID, hex
+	DID        string // Resolved from the candidates given to VerifySignature; empty if unknown
+	Submitter  string // Account that sent the anchor tx
+	Account    string // Signer account that approved a relayed anchor; empty if only the submitter vouches for it
+	Signature  []byte // Empty for records read from pre-multi-signer contracts
+	Timestamp  time.Time
+	HardwareID string
+	Revoked    bool
+	Legacy     bool // Anchored under the bare docHash, before per-signer anchoring
+}
+
+// SignerID is a signer's on-chain identifier: keccak256 of their DID
+func SignerID(did string) common.Hash {
+	return crypto.Keccak256Hash([]byte(did))
+}
+
+// signerAnchorKey is the record key for a signer on contracts that only have
+// the document-keyed anchorSignature
+func signerAnchorKey(docHash, signerID common.Hash) common.Hash {
+	return crypto.Keccak256Hash(docHash.Bytes(), signerID.Bytes())
+}
+
+// supportsMultiSigner probes for getDocumentSigners; contracts without it revert
+func (c *Client) supportsMultiSigner(ctx context.Context) (bool, error) {
+	_, err := c.registry.GetDocumentSigners(ctx, common.Hash{})
+	return probeResult(err)
+}
+
+// probeResult reads the outcome of calling a method a contract may lack. A
+// revert or undecodable output means it lacks it; any other error means the
+// node didn't answer, and is returned rather than taken for a missing method.
+func probeResult(err error) (bool, error) {
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, errUndecodable), strings.Contains(strings.ToLower(err.Error()), "revert"):
+		return false, nil
+	}
+	return false, err
+}
+
+// VerifySignature returns every signer anchored on-chain for docHash.
+// candidateDIDs label records with their DID (the chain only stores its
+// hash); on contracts without getDocumentSigners they are also the only
+// signers that can be looked up.
+func (c *Client) VerifySignature(ctx context.Context, docHash string, candidateDIDs []string) ([]SignerRecord, error) {
+	docHashBytes := common.HexToHash(docHash)
+
+	byID := make(map[common.Hash]string, len(candidateDIDs))
+	for _, did := range candidateDIDs {
+		byID[SignerID(did)] = did
+	}
+
+	var records []SignerRecord
+
+	// A document anchored before per-signer anchoring has a single record under its own hash
+	legacy, err := c.documentRecord(ctx, docHashBytes)
+	if err != nil {
+		return nil, err
+	}
+	if legacy != nil {
+		legacy.Legacy = true
+		records = append(records, *legacy)
+	}
+
+	if !c.multiSigner {
+		for id, did := range byID {
+			record, err := c.documentRecord(ctx, signerAnchorKey(docHashBytes, id))
+			if err != nil {
+				return nil, err
+			}
+			if record != nil {
+				record.SignerID = id.Hex()
+				record.DID = did
+				records = append(records, *record)
+			}
+		}
+		return records, nil
+	}
+
+	onchain, err := c.registry.GetDocumentSigners(ctx, docHashBytes)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range onchain {
+		record := signerRecordFrom(&r)
+		record.DID = byID[r.SignerId]
+		if record.Account, err = c.signerAccount(ctx, docHashBytes, r.SignerId); err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+
+	return records, nil
+}
+
+// signerRecordFrom converts a decoded per-signer record
+func signerRecordFrom(r *RegistrySignerRecord) *SignerRecord {
+	return &SignerRecord{
+		SignerID:   common.Hash(r.SignerId).Hex(),
+		Submitter:  r.Submitter.Hex(),
+		Signature:  r.PqcSignature,
+		Timestamp:  time.Unix(r.Timestamp.Int64(), 0),
+		HardwareID: common.Hash(r.HardwareID).Hex(),
+		Revoked:    r.IsRevoked,
+	}
+}
+
+// documentRecord reads the full document-keyed record under key, or nil if there is none
+func (c *Client) documentRecord(ctx context.Context, key common.Hash) (*SignerRecord, error) {
+	r, err := c.registry.Signatures(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if r.Timestamp.Sign() == 0 {
+		return nil, nil
+	}
+
+	return &SignerRecord{
+		Submitter:  r.SignerDID.Hex(),
+		Signature:  r.PqcSignature,
+		Timestamp:  time.Unix(r.Timestamp.Int64(), 0),
+		HardwareID: common.Hash(r.HardwareID).Hex(),
+		Revoked:    r.IsRevoked,
+	}, nil
+}
+
+// SignerRecordOf returns one signer's record for docHash, or nil if they
+// haven't anchored it
+func (c *Client) SignerRecordOf(ctx context.Context, docHash, signerDID string) (*SignerRecord, error) {
+	docHashBytes := common.HexToHash(docHash)
+	signerID := SignerID(signerDID)
+
+	var record *SignerRecord
+	if c.multiSigner {
+		r, err := c.registry.SignerRecords(ctx, docHashBytes, signerID)
+		if err != nil {
+			return nil, err
+		}
+		if r.Timestamp.Sign() == 0 {
+			return nil, nil
+		}
+		record = signerRecordFrom(r)
+		if record.Account, err = c.signerAccount(ctx, docHashBytes, signerID); err != nil {
+			return nil, err
+		}
+	} else {
+		r, err := c.documentRecord(ctx, signerAnchorKey(docHashBytes, signerID))
+		if err != nil || r == nil {
+			return nil, err
+		}
+		record = r
+		record.SignerID = signerID.Hex()
+	}
+	record.DID = signerDID
+	return record, nil
+}
+
+// IsDocumentAnchored reports whether any signature is anchored for docHash
+func (c *Client) IsDocumentAnchored(ctx context.Context, docHash string) (bool, error) {
+	return c.registry.IsDocumentAnchored(ctx, common.HexToHash(docHash))
+}
+
+// TotalDocuments returns how many documents the contract has anchored
+func (c *Client) TotalDocuments(ctx context.Context) (uint64, error) {
+	total, err := c.registry.GetTotalDocuments(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return total.Uint64(), nil
+}
+
+// IsVerifiedSigner reports whether address may anchor on the contract, or
+// approve anchors as a signer account
+func (c *Client) IsVerifiedSigner(ctx context.Context, address string) (bool, error) {
+	return c.registry.IsVerifiedSigner(ctx, common.HexToAddress(address))
+}
+
+// Registry returns the typed contract binding
+func (c *Client) Registry() *Registry {
+	return c.registry
+}
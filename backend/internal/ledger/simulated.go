@@ -0,0 +1,414 @@
+package ledger
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// simulatedBlockTime is how often the simulated chain seals a block
+const simulatedBlockTime = time.Second
+
+// simulatedMarkerLen leading zero bytes mark every simulated tx and block
+// hash (0x0000000000000000…), so none can pass for a real chain's
+const simulatedMarkerLen = 8
+
+// SimulatedContract is the registry address the simulated chain reports
+const SimulatedContract = "0x0000000000000000000000000000000000005111"
+
+// simulatedSubmitter is the account every simulated anchor is sent from
+var simulatedSubmitter = common.HexToAddress("0x0000000000000000000000000000000000005112")
+
+// Simulated is an in-memory InklessRegistry on a chain that seals a block
+// every second. It follows the multi-signer contract's rules, so reverts,
+// revocations and receipts behave as they would on a node, but its state
+// lives only as long as the process. Tx and block hashes are derived from
+// their contents and position, so the same sequence of calls always yields
+// the same hashes.
+type Simulated struct {
+	mu        sync.Mutex
+	genesis   time.Time
+	txCount   uint64
+	txs       map[common.Hash]uint64                        // Tx hash -> block it is sealed in
+	signers   map[common.Hash]map[common.Hash]*SignerRecord // docHash -> signerID -> record
+	documents map[common.Hash]*SignerRecord                 // Document-keyed records (batch roots)
+	verified  map[common.Address]bool                       // Verified signers; the submitter is one from genesis
+	events    []Event
+	subs      map[chan<- struct{}]struct{}
+}
+
+// NewSimulated starts an empty simulated chain at block 0
+func NewSimulated() *Simulated {
+	return &Simulated{
+		genesis:   time.Now().Truncate(time.Second),
+		txs:       make(map[common.Hash]uint64),
+		signers:   make(map[common.Hash]map[common.Hash]*SignerRecord),
+		documents: make(map[common.Hash]*SignerRecord),
+		verified:  map[common.Address]bool{simulatedSubmitter: true},
+		subs:      make(map[chan<- struct{}]struct{}),
+	}
+}
+
+// IsSimulatedHash reports whether a tx or block hash came from the simulated chain
+func IsSimulatedHash(hash string) bool {
+	h := common.HexToHash(hash)
+	return h != (common.Hash{}) && common.BytesToHash(h[:simulatedMarkerLen]) == (common.Hash{})
+}
+
+// Backend reports that the chain is simulated
+func (s *Simulated) Backend() string {
+	return BackendSimulated
+}
+
+// ContractAddress returns SimulatedContract
+func (s *Simulated) ContractAddress() string {
+	return SimulatedContract
+}
+
+// ChainStatus reports the simulated head; there is no balance to track
+func (s *Simulated) ChainStatus(ctx context.Context) (*ChainStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	head := s.head()
+	return &ChainStatus{
+		HeadBlock: head,
+		HeadTime:  s.blockTime(head),
+		Signer:    simulatedSubmitter.Hex(),
+	}, nil
+}
+
+// AnchorSignature records one signer's anchor, reverting if they already
+// anchored docHash. The signer's account is recorded as approving it once it
+// is a verified signer.
+func (s *Simulated) AnchorSignature(ctx context.Context, docHash, signerDID string, signature []byte, hardwareID string) (string, error) {
+	var account string
+	if AccountsEnabled() {
+		var err error
+		if account, err = AccountAddress(signerDID); err != nil {
+			return "", err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, signerID := common.HexToHash(docHash), SignerID(signerDID)
+	if s.signers[doc][signerID] != nil {
+		return "", fmt.Errorf("%w: signer already anchored this document", ErrWouldRevert)
+	}
+
+	method := "anchorSignerSignature"
+	if account != "" && s.verified[common.HexToAddress(account)] {
+		method = "anchorSignerSignatureFor"
+	} else {
+		account = ""
+	}
+	tx, block := s.send(method, doc[:], signerID[:], signature)
+	record := &SignerRecord{
+		SignerID:   signerID.Hex(),
+		Submitter:  simulatedSubmitter.Hex(),
+		Account:    account,
+		Signature:  append([]byte(nil), signature...),
+		Timestamp:  s.blockTime(block),
+		HardwareID: HardwareIDHash(hardwareID).Hex(),
+	}
+	if s.signers[doc] == nil {
+		s.signers[doc] = make(map[common.Hash]*SignerRecord)
+	}
+	s.signers[doc][signerID] = record
+
+	s.emit(Event{
+		Name:       EventSignerAnchored,
+		DocHash:    doc.Hex(),
+		SignerID:   signerID.Hex(),
+		HardwareID: record.HardwareID,
+		Timestamp:  record.Timestamp,
+	}, tx, block)
+	return tx.Hex(), nil
+}
+
+// AnchorBatchRoot records a batch root as a document-keyed record
+func (s *Simulated) AnchorBatchRoot(ctx context.Context, root string, descriptor []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := common.HexToHash(root)
+	if s.documents[key] != nil {
+		return "", fmt.Errorf("%w: batch root already anchored", ErrWouldRevert)
+	}
+
+	tx, block := s.send("anchorSignature", key[:], descriptor)
+	record := &SignerRecord{
+		Submitter:  simulatedSubmitter.Hex(),
+		Signature:  append([]byte(nil), descriptor...),
+		Timestamp:  s.blockTime(block),
+		HardwareID: batchHardwareID.Hex(),
+	}
+	s.documents[key] = record
+
+	s.emit(Event{
+		Name:       EventSignatureAnchored,
+		DocHash:    key.Hex(),
+		Account:    record.Submitter,
+		HardwareID: record.HardwareID,
+		Timestamp:  record.Timestamp,
+	}, tx, block)
+	return tx.Hex(), nil
+}
+
+// RevokeSignature revokes one signer's record, or with legacy the
+// document-keyed record under docHash
+func (s *Simulated) RevokeSignature(ctx context.Context, docHash, signerDID string, legacy bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, signerID := common.HexToHash(docHash), SignerID(signerDID)
+	record, name := s.signers[doc][signerID], EventSignerRevoked
+	if record == nil && legacy {
+		record, name = s.documents[doc], EventSignatureRevoked
+	}
+	if record == nil {
+		return "", ErrNotAnchored
+	}
+	if record.Revoked {
+		return "", fmt.Errorf("%w: already revoked", ErrWouldRevert)
+	}
+
+	tx, block := s.send("revoke", doc[:], signerID[:])
+	record.Revoked = true
+
+	e := Event{Name: name, DocHash: doc.Hex(), Account: simulatedSubmitter.Hex(), Timestamp: s.blockTime(block)}
+	if name == EventSignerRevoked {
+		e.SignerID = signerID.Hex()
+	}
+	s.emit(e, tx, block)
+	return tx.Hex(), nil
+}
+
+// VerifySignature returns every record for docHash: a document-keyed one
+// first, then each signer's in signer ID order
+func (s *Simulated) VerifySignature(ctx context.Context, docHash string, candidateDIDs []string) ([]SignerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := common.HexToHash(docHash)
+	byID := make(map[string]string, len(candidateDIDs))
+	for _, did := range candidateDIDs {
+		byID[SignerID(did).Hex()] = did
+	}
+
+	var records []SignerRecord
+	if legacy := s.documents[doc]; legacy != nil {
+		record := *legacy
+		record.Legacy = true
+		records = append(records, record)
+	}
+
+	signers := make([]SignerRecord, 0, len(s.signers[doc]))
+	for _, r := range s.signers[doc] {
+		record := *r
+		record.DID = byID[record.SignerID]
+		signers = append(signers, record)
+	}
+	sort.Slice(signers, func(i, j int) bool { return signers[i].SignerID < signers[j].SignerID })
+
+	return append(records, signers...), nil
+}
+
+// SignerRecordOf returns one signer's record for docHash, or nil if they haven't anchored it
+func (s *Simulated) SignerRecordOf(ctx context.Context, docHash, signerDID string) (*SignerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.signers[common.HexToHash(docHash)][SignerID(signerDID)]
+	if r == nil {
+		return nil, nil
+	}
+	record := *r
+	record.DID = signerDID
+	return &record, nil
+}
+
+// IsVerifiedSigner reports whether address may anchor, or approve anchors as a signer account
+func (s *Simulated) IsVerifiedSigner(ctx context.Context, address string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.verified[common.HexToAddress(address)], nil
+}
+
+// SetVerifiedSigner adds or removes a verified signer
+func (s *Simulated) SetVerifiedSigner(ctx context.Context, address string, verified bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	signer := common.HexToAddress(address)
+	if s.verified[signer] == verified {
+		return "", ErrSignerUnchanged
+	}
+
+	method, name := "removeVerifiedSigner", EventSignerRemoved
+	if verified {
+		method, name = "addVerifiedSigner", EventSignerVerified
+	}
+	tx, block := s.send(method, signer[:])
+	if verified {
+		s.verified[signer] = true
+	} else {
+		delete(s.verified, signer)
+	}
+
+	s.emit(Event{Name: name, Account: signer.Hex(), Timestamp: s.blockTime(block)}, tx, block)
+	return tx.Hex(), nil
+}
+
+// BatchRootRecord returns the record of an anchored batch root, or nil if it isn't anchored
+func (s *Simulated) BatchRootRecord(ctx context.Context, root string) (*SignerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.documents[common.HexToHash(root)]
+	if r == nil {
+		return nil, nil
+	}
+	record := *r
+	return &record, nil
+}
+
+// TransactionReceipt reports a tx pending until its block is sealed, then
+// confirmed one more time with every block after it. Simulated txs never revert.
+func (s *Simulated) TransactionReceipt(ctx context.Context, txHash string) (*Receipt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	block, ok := s.txs[common.HexToHash(txHash)]
+	if !ok {
+		return nil, ErrTxNotFound
+	}
+	head := s.head()
+	if block > head {
+		return &Receipt{Pending: true, Simulated: true}, nil
+	}
+	return &Receipt{
+		BlockNumber:   block,
+		BlockHash:     s.blockHash(block).Hex(),
+		BlockTime:     s.blockTime(block),
+		Confirmations: head - block + 1,
+		Simulated:     true,
+	}, nil
+}
+
+// ReplaceTransaction always fails: a simulated tx is sealed within a block time, never stuck
+func (s *Simulated) ReplaceTransaction(ctx context.Context, txHash string, bumpPercent int) (string, error) {
+	return "", ErrTxNotPending
+}
+
+// HeadBlock returns the newest sealed block
+func (s *Simulated) HeadBlock(ctx context.Context) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.head(), nil
+}
+
+// BlockHash returns the hash of a sealed block
+func (s *Simulated) BlockHash(ctx context.Context, number uint64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if number > s.head() {
+		return "", fmt.Errorf("failed to get block header: block %d not sealed", number)
+	}
+	return s.blockHash(number).Hex(), nil
+}
+
+// FilterEvents returns the events in blocks from..to inclusive, in chain order
+func (s *Simulated) FilterEvents(ctx context.Context, from, to uint64) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []Event
+	for _, e := range s.events {
+		if e.BlockNumber >= from && e.BlockNumber <= to {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// SubscribeEvents signals notify whenever the simulated contract emits an event
+func (s *Simulated) SubscribeEvents(ctx context.Context, notify chan<- struct{}) (ethereum.Subscription, error) {
+	s.mu.Lock()
+	s.subs[notify] = struct{}{}
+	s.mu.Unlock()
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case <-quit:
+		case <-ctx.Done():
+		}
+		s.mu.Lock()
+		delete(s.subs, notify)
+		s.mu.Unlock()
+		return nil
+	}), nil
+}
+
+// head is the newest sealed block. Callers hold mu.
+func (s *Simulated) head() uint64 {
+	return uint64(time.Since(s.genesis) / simulatedBlockTime)
+}
+
+// blockTime is the timestamp of block number
+func (s *Simulated) blockTime(number uint64) time.Time {
+	return s.genesis.Add(time.Duration(number) * simulatedBlockTime)
+}
+
+// blockHash derives block number's hash from its number alone
+func (s *Simulated) blockHash(number uint64) common.Hash {
+	return simulatedHash("block", binary.BigEndian.AppendUint64(nil, number))
+}
+
+// send assigns the next tx to the block being built and returns its hash,
+// derived from its position and calldata. Callers hold mu.
+func (s *Simulated) send(method string, args ...[]byte) (common.Hash, uint64) {
+	s.txCount++
+	parts := append([][]byte{[]byte(method), binary.BigEndian.AppendUint64(nil, s.txCount)}, args...)
+	tx := simulatedHash("tx", parts...)
+
+	block := s.head() + 1
+	s.txs[tx] = block
+	return tx, block
+}
+
+// emit appends an event logged by tx in block and nudges subscribers. Callers hold mu.
+func (s *Simulated) emit(e Event, tx common.Hash, block uint64) {
+	e.BlockNumber = block
+	e.BlockHash = s.blockHash(block).Hex()
+	e.TxHash = tx.Hex()
+	if n := len(s.events); n > 0 && s.events[n-1].BlockNumber == block {
+		e.LogIndex = s.events[n-1].LogIndex + 1
+	}
+	s.events = append(s.events, e)
+
+	for notify := range s.subs {
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// simulatedHash hashes parts under a domain tag and zeroes the marker bytes
+func simulatedHash(kind string, parts ...[]byte) common.Hash {
+	h := crypto.Keccak256Hash(append([][]byte{[]byte("inkless-simulated-" + kind)}, parts...)...)
+	clear(h[:simulatedMarkerLen])
+	return h
+}
@@ -0,0 +1,248 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Ledger health states
+const (
+	HealthOperational = "operational"
+	HealthDegraded    = "degraded" // Reachable, but lagging, syncing, short of funds or failing intermittently
+	HealthDown        = "down"     // Not connected, or every recent check failed
+)
+
+// downAfter is how many consecutive failed checks turn degraded into down
+const downAfter = 3
+
+// Health is one network's state as last observed by Supervise
+type Health struct {
+	Name         string     `json:"name"`    // Configured network name
+	Backend      string     `json:"backend"` // besu, simulated, or none
+	Network      string     `json:"network"`
+	Status       string     `json:"status"` // operational, degraded, down
+	ChainID      uint64     `json:"chainId,omitempty"`
+	HeadBlock    uint64     `json:"headBlock,omitempty"`
+	HeadTime     *time.Time `json:"headTime,omitempty"`
+	LagSeconds   int64      `json:"lagSeconds"`   // How far the head block's timestamp trails the clock
+	BlocksBehind uint64     `json:"blocksBehind"` // While the node syncs
+	Signer       string     `json:"signer,omitempty"`
+	BalanceWei   string     `json:"balanceWei,omitempty"`
+	LowBalance   bool       `json:"lowBalance"`
+	Failures     int        `json:"failures"` // Consecutive failed checks
+	LastError    string     `json:"lastError,omitempty"`
+	CheckedAt    *time.Time `json:"checkedAt,omitempty"`
+}
+
+var (
+	healthMu sync.RWMutex
+	healths  = map[string]Health{} // By network name
+)
+
+// CurrentHealth returns the latest health snapshot of the primary network
+func CurrentHealth() Health {
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+	if h, ok := healths[PrimaryNetwork()]; ok {
+		return h
+	}
+	return Health{Backend: "none", Network: networkName("none", 0), Status: HealthDown}
+}
+
+// NetworkHealth returns the latest health snapshot of every network, primary first
+func NetworkHealth() []Health {
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+	var all []Health
+	for _, n := range Networks() {
+		all = append(all, healths[n.Name])
+	}
+	return all
+}
+
+// SupervisorOptions configure Supervise
+type SupervisorOptions struct {
+	Fees FeeConfig
+
+	CheckInterval time.Duration // Between health checks once connected
+	MinBackoff    time.Duration // First reconnect delay, doubled after each failure
+	MaxBackoff    time.Duration // Upper bound on the reconnect delay
+	MaxLag        time.Duration // A head block older than this is degraded
+	LowBalance    *big.Int      // Alert when the signer holds less wei than this; nil disables
+}
+
+// Supervise keeps a network connected and its health current until ctx is
+// cancelled. Each configured network has its own. When the network is not
+// connected yet it dials the node,
+// retrying with backoff, so a node that is down at boot is picked up once it
+// comes back. Once connected it checks the head block, sync progress and
+// signer balance every CheckInterval. The RPC client redials a dropped
+// connection on its next call, so a node that goes away later only needs to
+// be noticed, and the nonce reloaded when it returns.
+func Supervise(ctx context.Context, network NetworkConfig, opts SupervisorOptions) {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = 30 * time.Second
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = 5 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 5 * time.Minute
+	}
+	if opts.MaxLag <= 0 {
+		opts.MaxLag = 2 * time.Minute
+	}
+
+	backoff := opts.MinBackoff
+	for ctx.Err() == nil {
+		wait := opts.CheckInterval
+		if l := Network(network.Name); l == nil {
+			if err := Connect(network, opts.Fees); err != nil {
+				recordFailure(network.Name, err)
+				log.Printf("[Ledger] Network %s connection failed, retrying in %s: %v", network.Name, backoff, err)
+				wait, backoff = backoff, min(backoff*2, opts.MaxBackoff)
+			} else {
+				backoff = opts.MinBackoff
+				wait = 0
+			}
+		} else {
+			check(ctx, network.Name, l, opts)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(wait):
+		}
+	}
+}
+
+// checkTimeout bounds one health check
+const checkTimeout = 15 * time.Second
+
+// check reads a network's status and updates its health snapshot, logging
+// transitions and low-balance alerts
+func check(ctx context.Context, name string, l Ledger, opts SupervisorOptions) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	status, err := l.ChainStatus(ctx)
+	if err != nil {
+		if !errors.Is(ctx.Err(), context.Canceled) { // Not shutting down
+			recordFailure(name, err)
+		}
+		return
+	}
+
+	healthMu.RLock()
+	prev := healths[name]
+	healthMu.RUnlock()
+	if prev.Failures > 0 {
+		log.Printf("[Ledger] Network %s reachable again after %d failed attempts", name, prev.Failures)
+		if client, ok := l.(*Client); ok {
+			if err := client.resyncNonces(ctx); err != nil {
+				log.Printf("[Ledger] Failed to reload nonce: %v", err)
+			}
+		}
+	}
+
+	now := time.Now()
+	headTime := status.HeadTime
+	next := Health{
+		Name:         name,
+		Backend:      l.Backend(),
+		Network:      networkName(l.Backend(), status.ChainID),
+		Status:       HealthOperational,
+		ChainID:      status.ChainID,
+		HeadBlock:    status.HeadBlock,
+		HeadTime:     &headTime,
+		LagSeconds:   int64(now.Sub(headTime).Seconds()),
+		BlocksBehind: status.BlocksBehind,
+		Signer:       status.Signer,
+		CheckedAt:    &now,
+	}
+	if next.LagSeconds < 0 {
+		next.LagSeconds = 0
+	}
+	if status.Balance != nil {
+		next.BalanceWei = status.Balance.String()
+		next.LowBalance = opts.LowBalance != nil && status.Balance.Cmp(opts.LowBalance) < 0
+	}
+
+	switch {
+	case next.LowBalance:
+		next.Status = HealthDegraded
+		next.LastError = "signer balance is low"
+	case status.BlocksBehind > 0:
+		next.Status = HealthDegraded
+		next.LastError = "node is syncing"
+	case now.Sub(headTime) > opts.MaxLag:
+		next.Status = HealthDegraded
+		next.LastError = "head block is stale"
+	}
+
+	if next.LowBalance && !prev.LowBalance {
+		log.Printf("[Ledger] ALERT: network %s signer %s balance %s ETH is below %s ETH; anchoring will stop when it runs out",
+			name, status.Signer, formatEther(status.Balance), formatEther(opts.LowBalance))
+	}
+	if next.Status != prev.Status {
+		log.Printf("[Ledger] Network %s health %s -> %s (block %d, %ds behind)", name, prev.Status, next.Status, next.HeadBlock, next.LagSeconds)
+	}
+
+	healthMu.Lock()
+	healths[name] = next
+	healthMu.Unlock()
+}
+
+// recordFailure counts a failed connection attempt or check. A network that
+// was reachable is degraded until downAfter checks in a row have failed.
+func recordFailure(name string, err error) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	now := time.Now()
+	health := healths[name]
+	prev := health.Status
+	health.Name = name
+	health.Failures++
+	health.LastError = err.Error()
+	health.CheckedAt = &now
+	if Network(name) == nil || health.Failures >= downAfter {
+		health.Status = HealthDown
+	} else {
+		health.Status = HealthDegraded
+	}
+	healths[name] = health
+	if health.Status != prev {
+		log.Printf("[Ledger] Network %s health %s -> %s: %v", name, prev, health.Status, err)
+	}
+}
+
+// networkName labels the chain for display
+func networkName(backend string, chainID uint64) string {
+	switch backend {
+	case "none":
+		return "No ledger"
+	case BackendSimulated:
+		return "Simulated chain"
+	}
+	switch chainID {
+	case 137:
+		return "Polygon PoS"
+	case 80002:
+		return "Polygon Amoy"
+	case 1337, 2018:
+		return "Besu dev network"
+	}
+	return "Besu network"
+}
+
+// formatEther renders wei as a decimal ether amount
+func formatEther(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether)).Text('f', 6)
+}
@@ -0,0 +1,267 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TxSigner signs transactions for the account anchors are sent from
+type TxSigner interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// Remote signer JSON-RPC methods
+const (
+	RemoteMethodWeb3Signer = "eth_signTransaction"     // Web3Signer; returns the raw tx
+	RemoteMethodClef       = "account_signTransaction" // Clef; returns {raw, tx}
+)
+
+// SignerConfig selects where the transaction key lives. Exactly one of a raw
+// key, a keystore file or a remote signer is set; the last two keep the raw
+// key out of the process environment.
+type SignerConfig struct {
+	PrivateKey string // Hex key; for development
+
+	KeystoreFile         string // Encrypted go-ethereum (V3) keystore file
+	KeystorePasswordFile string // File holding the keystore's passphrase
+
+	RemoteURL     string // Web3Signer or Clef JSON-RPC endpoint
+	RemoteAddress string // Account to sign for; empty takes the signer's first account
+	RemoteMethod  string // eth_signTransaction (default) or account_signTransaction
+}
+
+// Kind names the configured key source: key, keystore, remote, or empty if none is
+func (s SignerConfig) Kind() string {
+	switch {
+	case s.RemoteURL != "":
+		return "remote"
+	case s.KeystoreFile != "":
+		return "keystore"
+	case s.PrivateKey != "":
+		return "key"
+	}
+	return ""
+}
+
+// NewTxSigner builds the signer cfg describes
+func NewTxSigner(ctx context.Context, cfg SignerConfig) (TxSigner, error) {
+	set := 0
+	for _, v := range []string{cfg.PrivateKey, cfg.KeystoreFile, cfg.RemoteURL} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, errors.New("configure one of a private key, a keystore file or a remote signer")
+	}
+
+	switch cfg.Kind() {
+	case "remote":
+		return NewRemoteSigner(ctx, cfg.RemoteURL, cfg.RemoteAddress, cfg.RemoteMethod)
+	case "keystore":
+		return NewKeystoreSigner(cfg.KeystoreFile, cfg.KeystorePasswordFile)
+	case "key":
+		return NewKeySigner(cfg.PrivateKey)
+	}
+	return nil, errors.New("no transaction signer configured")
+}
+
+// KeySigner signs with a private key held in memory
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewKeySigner parses a hex private key, with or without 0x
+func NewKeySigner(privateKeyHex string) (*KeySigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return &KeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}, nil
+}
+
+// NewKeystoreSigner decrypts a go-ethereum keystore file with the passphrase
+// in passwordFile. Only the encrypted file and the passphrase's path need be
+// configured; the key itself never passes through the environment.
+func NewKeystoreSigner(keystoreFile, passwordFile string) (*KeySigner, error) {
+	keyJSON, err := os.ReadFile(keystoreFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	var password []byte
+	if passwordFile != "" {
+		if password, err = os.ReadFile(passwordFile); err != nil {
+			return nil, fmt.Errorf("failed to read keystore password: %w", err)
+		}
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, strings.TrimRight(string(password), "\r\n"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return &KeySigner{key: key.PrivateKey, address: key.Address}, nil
+}
+
+// Address returns the signing account
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx signs tx for chainID
+func (s *KeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// RemoteSigner has a Web3Signer or Clef instance sign over JSON-RPC, so the
+// key stays in the signer's vault, KMS or HSM
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+	method  string
+}
+
+// NewRemoteSigner connects to the signer at url. With no address it signs
+// for the first account the signer lists.
+func NewRemoteSigner(ctx context.Context, url, address, method string) (*RemoteSigner, error) {
+	switch method {
+	case "":
+		method = RemoteMethodWeb3Signer
+	case RemoteMethodWeb3Signer, RemoteMethodClef:
+	default:
+		return nil, fmt.Errorf("unknown remote signer method %q: use %s or %s", method, RemoteMethodWeb3Signer, RemoteMethodClef)
+	}
+
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote signer: %w", err)
+	}
+	s := &RemoteSigner{client: client, method: method}
+
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			client.Close()
+			return nil, fmt.Errorf("invalid remote signer address: %s", address)
+		}
+		s.address = common.HexToAddress(address)
+		return s, nil
+	}
+
+	listMethod := "eth_accounts"
+	if method == RemoteMethodClef {
+		listMethod = "account_list"
+	}
+	var accounts []common.Address
+	if err := client.CallContext(ctx, &accounts, listMethod); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to list remote signer accounts: %w", err)
+	}
+	if len(accounts) == 0 {
+		client.Close()
+		return nil, errors.New("remote signer has no accounts")
+	}
+	s.address = accounts[0]
+	return s, nil
+}
+
+// remoteTxArgs is a transaction as the signer's JSON-RPC API expects it
+type remoteTxArgs struct {
+	From                 common.MixedcaseAddress  `json:"from"`
+	To                   *common.MixedcaseAddress `json:"to,omitempty"`
+	Gas                  hexutil.Uint64           `json:"gas"`
+	GasPrice             *hexutil.Big             `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big             `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big             `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big             `json:"value"`
+	Nonce                hexutil.Uint64           `json:"nonce"`
+	Data                 hexutil.Bytes            `json:"data"`
+	ChainID              *hexutil.Big             `json:"chainId"`
+}
+
+// Address returns the signing account
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx asks the remote signer to sign tx, then checks that what came back
+// is tx, signed by the expected account
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := remoteTxArgs{
+		From:    common.NewMixedcaseAddress(s.address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   (*hexutil.Big)(tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if to := tx.To(); to != nil {
+		mixed := common.NewMixedcaseAddress(*to)
+		args.To = &mixed
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	var result json.RawMessage
+	if err := s.client.CallContext(ctx, &result, s.method, args); err != nil {
+		return nil, fmt.Errorf("remote signer refused: %w", err)
+	}
+	raw, err := decodeSignedTx(result)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("remote signer returned a malformed transaction: %w", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an unverifiable transaction: %w", err)
+	}
+	if from != s.address || signed.Nonce() != tx.Nonce() || signed.Gas() != tx.Gas() ||
+		signed.Value().Cmp(tx.Value()) != 0 || signed.GasFeeCap().Cmp(tx.GasFeeCap()) > 0 ||
+		!bytes.Equal(signed.Data(), tx.Data()) || signed.To() == nil || tx.To() == nil || *signed.To() != *tx.To() {
+		return nil, errors.New("remote signer returned a different transaction than requested")
+	}
+	return signed, nil
+}
+
+// decodeSignedTx reads the raw tx from eth_signTransaction's hex string or
+// account_signTransaction's {raw, tx} object
+func decodeSignedTx(result json.RawMessage) ([]byte, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err == nil {
+		return raw, nil
+	}
+	var response struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := json.Unmarshal(result, &response); err != nil || len(response.Raw) == 0 {
+		return nil, errors.New("remote signer returned no transaction")
+	}
+	return response.Raw, nil
+}
+
+// Close disconnects from the signer
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,287 @@
+package ledger
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+)
+
+var testChainID = big.NewInt(1337)
+
+// fakeSigner stands in for Web3Signer (eth_*) and Clef (account_*). It signs
+// what it is asked to with key, after tamper has had a go at the tx.
+type fakeSigner struct {
+	key    *ecdsa.PrivateKey
+	tamper func(tx *types.DynamicFeeTx)
+}
+
+func (f *fakeSigner) sign(args remoteTxArgs) (*types.Transaction, error) {
+	inner := &types.DynamicFeeTx{
+		ChainID: (*big.Int)(args.ChainID),
+		Nonce:   uint64(args.Nonce),
+		Gas:     uint64(args.Gas),
+		Value:   (*big.Int)(args.Value),
+		Data:    args.Data,
+	}
+	if args.To != nil {
+		to := args.To.Address()
+		inner.To = &to
+	}
+	if args.MaxFeePerGas != nil {
+		inner.GasFeeCap = (*big.Int)(args.MaxFeePerGas)
+		inner.GasTipCap = (*big.Int)(args.MaxPriorityFeePerGas)
+	} else {
+		inner.GasFeeCap = (*big.Int)(args.GasPrice)
+		inner.GasTipCap = (*big.Int)(args.GasPrice)
+	}
+	if f.tamper != nil {
+		f.tamper(inner)
+	}
+
+	var tx *types.Transaction
+	if args.MaxFeePerGas != nil {
+		tx = types.NewTx(inner)
+	} else {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce: inner.Nonce, GasPrice: inner.GasFeeCap, Gas: inner.Gas,
+			To: inner.To, Value: inner.Value, Data: inner.Data,
+		})
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(inner.ChainID), f.key)
+}
+
+// web3SignerAPI serves eth_accounts and eth_signTransaction
+type web3SignerAPI struct{ f *fakeSigner }
+
+func (a *web3SignerAPI) Accounts() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(a.f.key.PublicKey)}
+}
+
+func (a *web3SignerAPI) SignTransaction(args remoteTxArgs) (hexutil.Bytes, error) {
+	tx, err := a.f.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+// clefAPI serves account_list and account_signTransaction
+type clefAPI struct{ f *fakeSigner }
+
+type clefSignResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func (a *clefAPI) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(a.f.key.PublicKey)}
+}
+
+func (a *clefAPI) SignTransaction(args remoteTxArgs) (*clefSignResult, error) {
+	tx, err := a.f.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &clefSignResult{Raw: raw, Tx: tx}, nil
+}
+
+// newFakeSigner serves f's eth and account namespaces over HTTP JSON-RPC
+func newFakeSigner(t *testing.T, f *fakeSigner) string {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &web3SignerAPI{f}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("account", &clefAPI{f}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(server)
+	t.Cleanup(func() {
+		srv.Close()
+		server.Stop()
+	})
+	return srv.URL
+}
+
+func mustKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testTx(dynamic bool) *types.Transaction {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	if dynamic {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID: testChainID, Nonce: 7, Gas: 100000, To: &to, Value: big.NewInt(0),
+			GasFeeCap: big.NewInt(2e9), GasTipCap: big.NewInt(1e9), Data: []byte{0xde, 0xad, 0xbe, 0xef},
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce: 7, Gas: 100000, To: &to, Value: big.NewInt(0),
+		GasPrice: big.NewInt(2e9), Data: []byte{0xde, 0xad, 0xbe, 0xef},
+	})
+}
+
+func TestRemoteSignerSignsTransactions(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		dynamic bool
+	}{
+		{"eth_signTransaction", RemoteMethodWeb3Signer, true},
+		{"eth_signTransaction legacy", "", false},
+		{"account_signTransaction", RemoteMethodClef, true},
+		{"account_signTransaction legacy", RemoteMethodClef, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := mustKey(t)
+			url := newFakeSigner(t, &fakeSigner{key: key})
+
+			// No address: the first account eth_accounts / account_list returns
+			s, err := NewRemoteSigner(context.Background(), url, "", tt.method)
+			if err != nil {
+				t.Fatalf("NewRemoteSigner: %v", err)
+			}
+			defer s.Close()
+			if want := crypto.PubkeyToAddress(key.PublicKey); s.Address() != want {
+				t.Fatalf("Address = %s, want %s", s.Address(), want)
+			}
+
+			tx := testTx(tt.dynamic)
+			signed, err := s.SignTx(context.Background(), tx, testChainID)
+			if err != nil {
+				t.Fatalf("SignTx: %v", err)
+			}
+			from, err := types.Sender(types.LatestSignerForChainID(testChainID), signed)
+			if err != nil || from != s.Address() {
+				t.Fatalf("sender = %s, %v", from, err)
+			}
+			if signed.Type() != tx.Type() || signed.Nonce() != tx.Nonce() || string(signed.Data()) != string(tx.Data()) {
+				t.Errorf("signed a different transaction: %+v", signed)
+			}
+		})
+	}
+}
+
+func TestRemoteSignerRejectsTamperedTransactions(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(tx *types.DynamicFeeTx)
+		key    bool // Sign with a key other than the account's
+	}{
+		{name: "different nonce", tamper: func(tx *types.DynamicFeeTx) { tx.Nonce++ }},
+		{name: "different data", tamper: func(tx *types.DynamicFeeTx) { tx.Data = []byte{0x01} }},
+		{name: "different to-address", tamper: func(tx *types.DynamicFeeTx) {
+			to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+			tx.To = &to
+		}},
+		{name: "higher fee cap", tamper: func(tx *types.DynamicFeeTx) { tx.GasFeeCap = big.NewInt(9e9) }},
+		{name: "wrong signer", key: true},
+	}
+
+	for _, method := range []string{RemoteMethodWeb3Signer, RemoteMethodClef} {
+		for _, tt := range tests {
+			t.Run(method+"/"+tt.name, func(t *testing.T) {
+				account := mustKey(t)
+				f := &fakeSigner{key: account, tamper: tt.tamper}
+				if tt.key {
+					f.key = mustKey(t)
+				}
+				url := newFakeSigner(t, f)
+
+				s, err := NewRemoteSigner(context.Background(), url, crypto.PubkeyToAddress(account.PublicKey).Hex(), method)
+				if err != nil {
+					t.Fatalf("NewRemoteSigner: %v", err)
+				}
+				defer s.Close()
+
+				if _, err := s.SignTx(context.Background(), testTx(true), testChainID); err == nil ||
+					!strings.Contains(err.Error(), "different transaction") {
+					t.Fatalf("err = %v, want a rejected transaction", err)
+				}
+			})
+		}
+	}
+}
+
+func TestNewRemoteSignerRejectsBadConfig(t *testing.T) {
+	url := newFakeSigner(t, &fakeSigner{key: mustKey(t)})
+
+	if _, err := NewRemoteSigner(context.Background(), url, "", "personal_sign"); err == nil {
+		t.Error("accepted an unknown method")
+	}
+	if _, err := NewRemoteSigner(context.Background(), url, "not-an-address", ""); err == nil {
+		t.Error("accepted an invalid address")
+	}
+}
+
+func TestNewKeystoreSigner(t *testing.T) {
+	key := mustKey(t)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.New(),
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}, "correct horse", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	keystoreFile := filepath.Join(dir, "key.json")
+	passwordFile := filepath.Join(dir, "password")
+	wrongFile := filepath.Join(dir, "wrong")
+	for file, content := range map[string][]byte{
+		keystoreFile: keyJSON,
+		passwordFile: []byte("correct horse\n"), // Trailing newline as editors leave it
+		wrongFile:    []byte("battery staple"),
+	} {
+		if err := os.WriteFile(file, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := NewKeystoreSigner(keystoreFile, passwordFile)
+	if err != nil {
+		t.Fatalf("NewKeystoreSigner: %v", err)
+	}
+	if want := crypto.PubkeyToAddress(key.PublicKey); s.Address() != want {
+		t.Fatalf("Address = %s, want %s", s.Address(), want)
+	}
+	signed, err := s.SignTx(context.Background(), testTx(true), testChainID)
+	if err != nil {
+		t.Fatalf("SignTx: %v", err)
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(testChainID), signed); err != nil || from != s.Address() {
+		t.Errorf("sender = %s, %v", from, err)
+	}
+
+	if _, err := NewKeystoreSigner(keystoreFile, wrongFile); err == nil {
+		t.Error("decrypted with the wrong password")
+	}
+	if _, err := NewKeystoreSigner(filepath.Join(dir, "missing.json"), passwordFile); err == nil {
+		t.Error("accepted a missing keystore file")
+	}
+}
@@ -0,0 +1,92 @@
+// Package merkle builds the keccak256 Merkle trees used for batched anchoring.
+// Leaves and interior nodes carry different one-byte prefixes (0x00, 0x01) so
+// an interior node can never be passed off as a leaf. A node without a
+// sibling is promoted to the next level unchanged.
+package merkle
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// ProofStep is one sibling on the path from a leaf to the root
+type ProofStep struct {
+	Hash string `json:"hash"` // 0x-prefixed hex
+	Left bool   `json:"left"` // Sibling sits to the left: parent = H(0x01 || sibling || current)
+}
+
+// Tree is a complete Merkle tree, kept level by level from the leaves up
+type Tree struct {
+	levels [][]common.Hash
+}
+
+// Leaf hashes the concatenation of parts as a leaf: keccak256(0x00 || parts...)
+func Leaf(parts ...[]byte) common.Hash {
+	return crypto.Keccak256Hash(append([][]byte{{leafPrefix}}, parts...)...)
+}
+
+func node(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte{nodePrefix}, left.Bytes(), right.Bytes())
+}
+
+// Build creates a tree over leaves, in order. It panics on an empty slice.
+func Build(leaves []common.Hash) *Tree {
+	if len(leaves) == 0 {
+		panic("merkle: no leaves")
+	}
+
+	level := append([]common.Hash(nil), leaves...)
+	t := &Tree{levels: [][]common.Hash{level}}
+	for len(level) > 1 {
+		next := make([]common.Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, node(level[i], level[i+1]))
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+	return t
+}
+
+// Root returns the tree's root
+func (t *Tree) Root() common.Hash {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Proof returns the inclusion proof for the leaf at index
+func (t *Tree) Proof(index int) []ProofStep {
+	var proof []ProofStep
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, ProofStep{Hash: level[sibling].Hex(), Left: sibling < index})
+		}
+		index /= 2
+	}
+	return proof
+}
+
+// Verify checks that leaf is included under root
+func Verify(leaf common.Hash, proof []ProofStep, root common.Hash) bool {
+	current := leaf
+	for _, step := range proof {
+		sibling := common.HexToHash(step.Hash)
+		if step.Left {
+			current = node(sibling, current)
+		} else {
+			current = node(current, sibling)
+		}
+	}
+	return bytes.Equal(current.Bytes(), root.Bytes())
+}
@@ -0,0 +1,339 @@
+// Package reconcile audits signature_metadata against the InklessRegistry
+// contract. It reads the contract directly rather than the indexer's mirror,
+// so it also works where the indexer is disabled or behind.
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/inkless/backend/internal/anchoring"
+	"github.com/inkless/backend/internal/db/models"
+	"github.com/inkless/backend/internal/ledger"
+	"github.com/inkless/backend/internal/signing"
+	"gorm.io/gorm"
+)
+
+// Issues a row can be reported for
+const (
+	IssueMockTxHash     = "mock_tx_hash"      // Carries a hash minted in mock mode; nothing reached the chain
+	IssueSimulated      = "simulated_anchor"  // Anchored on the simulated chain, never on this one
+	IssueMissingAnchor  = "missing_anchor"    // Claims it was anchored; the contract has no record
+	IssueWrongSigner    = "wrong_signer"      // The document is anchored on-chain, but only by signers Postgres doesn't have
+	IssueHardwareID     = "hardware_mismatch" // The on-chain device hash differs from the row's
+	IssueRevokedOnChain = "revoked_on_chain"  // Revoked on-chain, still active in Postgres
+)
+
+// Actions taken in fix mode
+const (
+	ActionRequeued      = "requeued"
+	ActionMarkedFailed  = "marked_failed"
+	ActionMarkedRevoked = "marked_revoked"
+)
+
+// Finding is one issue on one signature row
+type Finding struct {
+	SignatureID string `json:"signatureId"`
+	DocHash     string `json:"docHash"`
+	SignerDID   string `json:"signerDid"`
+	Status      string `json:"status"` // Before any fix
+	TxHash      string `json:"txHash,omitempty"`
+	Issue       string `json:"issue"`
+	Detail      string `json:"detail,omitempty"`
+	Action      string `json:"action,omitempty"` // Fix mode only; empty when the row was left alone
+	FixError    string `json:"fixError,omitempty"`
+}
+
+// Options tune a reconciliation run
+type Options struct {
+	Network     string        // Connected network to audit; rows anchored on others are skipped
+	Fix         bool          // Re-queue missing anchors and mark mismatched rows
+	PageSize    int           // Documents loaded per query
+	ReadTimeout time.Duration // Bound on each contract read
+}
+
+// Reconciler walks every signature row and compares it with the contract
+type Reconciler struct {
+	db     *gorm.DB
+	opts   Options
+	ledger ledger.Ledger
+}
+
+// issue is a finding before it is tied to a row
+type issue struct {
+	kind   string
+	detail string
+}
+
+// New creates a reconciler, filling in defaults for unset options. The
+// network must be connected.
+func New(database *gorm.DB, opts Options) *Reconciler {
+	if opts.Network == "" {
+		opts.Network = ledger.PrimaryNetwork()
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 200
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = 30 * time.Second
+	}
+	return &Reconciler{db: database, opts: opts, ledger: ledger.Network(opts.Network)}
+}
+
+// Run audits every document in doc hash order. It stops at the first failed
+// read, returning what it found up to then alongside the error.
+func (r *Reconciler) Run(ctx context.Context) ([]Finding, error) {
+	findings := []Finding{}
+	roots := make(map[string]*ledger.SignerRecord)
+
+	for offset := 0; ; offset += r.opts.PageSize {
+		var docHashes []string
+		if err := r.db.WithContext(ctx).
+			Model(&models.SignatureMetadata{}).
+			Distinct("doc_hash").
+			Order("doc_hash").
+			Offset(offset).
+			Limit(r.opts.PageSize).
+			Pluck("doc_hash", &docHashes).Error; err != nil {
+			return findings, err
+		}
+
+		for _, docHash := range docHashes {
+			found, err := r.document(ctx, docHash, roots)
+			if err != nil {
+				return findings, fmt.Errorf("reconcile %s: %w", docHash, err)
+			}
+			findings = append(findings, found...)
+		}
+		if len(docHashes) < r.opts.PageSize {
+			return findings, nil
+		}
+	}
+}
+
+// document checks every signature on one document against its on-chain
+// records. Rows predating named networks belong to the primary.
+func (r *Reconciler) document(ctx context.Context, docHash string, roots map[string]*ledger.SignerRecord) ([]Finding, error) {
+	networks := []string{r.opts.Network}
+	if r.opts.Network == ledger.PrimaryNetwork() {
+		networks = append(networks, "")
+	}
+
+	var sigs []models.SignatureMetadata
+	if err := r.db.WithContext(ctx).
+		Preload("Signer").
+		Where("doc_hash = ? AND COALESCE(ledger_network, '') IN ?", docHash, networks).
+		Order("created_at asc").
+		Find(&sigs).Error; err != nil {
+		return nil, err
+	}
+	if len(sigs) == 0 {
+		return nil, nil
+	}
+
+	dids := make([]string, len(sigs))
+	for i, sig := range sigs {
+		dids[i] = sig.Signer.DIDAddress
+	}
+	readCtx, cancel := context.WithTimeout(ctx, r.opts.ReadTimeout)
+	records, err := r.ledger.VerifySignature(readCtx, docHash, dids)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	// A legacy document-keyed record predates co-signing, so it belongs to the first signer
+	byDID := make(map[string]*ledger.SignerRecord, len(records))
+	var unknown []*ledger.SignerRecord
+	for i := range records {
+		switch {
+		case records[i].Legacy && len(sigs) > 0:
+			byDID[sigs[0].Signer.DIDAddress] = &records[i]
+		case records[i].DID != "":
+			byDID[records[i].DID] = &records[i]
+		default:
+			unknown = append(unknown, &records[i])
+		}
+	}
+
+	var findings []Finding
+	for i := range sigs {
+		sig := &sigs[i]
+		record, batched := byDID[sig.Signer.DIDAddress], false
+		if record == nil && sig.MerkleRoot != "" {
+			if record, err = r.batchRoot(ctx, roots, sig.MerkleRoot); err != nil {
+				return nil, err
+			}
+			batched = record != nil
+		}
+
+		issues := check(sig, record, batched, unknown)
+		if len(issues) == 0 {
+			continue
+		}
+
+		var action, fixErr string
+		if r.opts.Fix {
+			var err error
+			if action, err = r.fix(ctx, sig, issues); err != nil {
+				fixErr = err.Error()
+			}
+		}
+
+		for _, is := range issues {
+			f := Finding{
+				SignatureID: sig.ID.String(),
+				DocHash:     sig.DocHash,
+				SignerDID:   sig.Signer.DIDAddress,
+				Status:      sig.Status,
+				Issue:       is.kind,
+				Detail:      is.detail,
+				Action:      action,
+				FixError:    fixErr,
+			}
+			if sig.LedgerTxHash != nil {
+				f.TxHash = *sig.LedgerTxHash
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// batchRoot reads a batch root's on-chain record once per run
+func (r *Reconciler) batchRoot(ctx context.Context, cache map[string]*ledger.SignerRecord, root string) (*ledger.SignerRecord, error) {
+	if record, seen := cache[root]; seen {
+		return record, nil
+	}
+
+	readCtx, cancel := context.WithTimeout(ctx, r.opts.ReadTimeout)
+	defer cancel()
+
+	record, err := r.ledger.BatchRootRecord(readCtx, root)
+	if err != nil {
+		return nil, err
+	}
+	cache[root] = record
+	return record, nil
+}
+
+// check lists what is wrong with sig given its on-chain record, which is nil
+// if it has none. unknown are the document's records no row accounts for.
+func check(sig *models.SignatureMetadata, record *ledger.SignerRecord, batched bool, unknown []*ledger.SignerRecord) []issue {
+	var issues []issue
+
+	if sig.LedgerTxHash != nil && anchoring.IsMockTxHash(*sig.LedgerTxHash) {
+		issues = append(issues, issue{IssueMockTxHash, "tx hash was minted while no ledger was configured"})
+	} else if sig.LedgerBackend == ledger.BackendSimulated {
+		issues = append(issues, issue{IssueSimulated, "anchored on the in-memory simulated chain"})
+	}
+
+	if record == nil {
+		// A mock or simulated anchor already explains the missing record
+		if !anchoring.ExpectsChainRecord(sig) || len(issues) > 0 {
+			return issues
+		}
+		signerID := ledger.SignerID(sig.Signer.DIDAddress).Hex()
+		switch {
+		case len(unknown) > 0:
+			issues = append(issues, issue{IssueWrongSigner, fmt.Sprintf("anchored on-chain by signer %s, not %s", unknown[0].SignerID, signerID)})
+		case sig.MerkleRoot != "":
+			issues = append(issues, issue{IssueMissingAnchor, "no on-chain record for batch root " + sig.MerkleRoot})
+		default:
+			issues = append(issues, issue{IssueMissingAnchor, "no on-chain record for signer " + signerID})
+		}
+		return issues
+	}
+
+	// A batch root carries no device
+	if !batched {
+		if want := ledger.HardwareIDHash(sig.HardwareID).Hex(); record.HardwareID != want {
+			issues = append(issues, issue{IssueHardwareID, fmt.Sprintf("chain has %s, row hashes to %s", record.HardwareID, want)})
+		}
+	}
+
+	if record.Revoked && sig.Status != anchoring.SignatureRevoked {
+		issues = append(issues, issue{IssueRevokedOnChain, "on-chain record is revoked"})
+	}
+
+	return issues
+}
+
+// fix applies the remedy for the row's most serious issue and records it in
+// the audit log. A row revoked on-chain follows the chain; a row the chain
+// contradicts is marked failed; a row whose anchor never reached the chain is
+// re-queued. A row already revoked is left as it is.
+func (r *Reconciler) fix(ctx context.Context, sig *models.SignatureMetadata, issues []issue) (string, error) {
+	has := func(kind string) bool {
+		for _, is := range issues {
+			if is.kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	var action string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		switch {
+		case sig.Status == anchoring.SignatureRevoked:
+			// A revocation is never undone or rewritten; the finding is reported only
+
+		case has(IssueRevokedOnChain):
+			action = ActionMarkedRevoked
+			return tx.Model(sig).Updates(map[string]interface{}{
+				"status":            anchoring.SignatureRevoked,
+				"revoked_at":        now,
+				"revocation_reason": "other",
+				"revocation_note":   "Revoked on-chain; found by reconciliation",
+			}).Error
+
+		case has(IssueWrongSigner), has(IssueHardwareID):
+			action = ActionMarkedFailed
+			return tx.Model(sig).Update("status", anchoring.SignatureFailed).Error
+
+		default:
+			action = ActionRequeued
+			return anchoring.Requeue(tx, sig, sig.Signer.DIDAddress, payload(sig))
+		}
+		return nil
+	})
+	if err != nil || action == "" {
+		return "", err
+	}
+
+	kinds := make([]string, len(issues))
+	for i, is := range issues {
+		kinds[i] = is.kind
+	}
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"signatureId": sig.ID,
+		"docHash":     sig.DocHash,
+		"issues":      kinds,
+		"action":      action,
+		"priorStatus": sig.Status,
+	})
+	metadataStr := string(metadata)
+	r.db.Create(&models.AuditLog{
+		UserID:     sig.SignerID,
+		ActionType: "signature_reconcile",
+		Metadata:   &metadataStr,
+		Timestamp:  time.Now(),
+	})
+
+	return action, nil
+}
+
+// payload rebuilds the bytes the API anchors for sig, for rows with no job to copy them from
+func payload(sig *models.SignatureMetadata) []byte {
+	if len(sig.ClassicalSignature) == 0 {
+		return sig.PQCSignature
+	}
+	return signing.EncodeComposite(
+		signing.Component{Algorithm: signing.Algorithm(sig.Algorithm), Signature: sig.PQCSignature},
+		signing.Component{Algorithm: signing.Algorithm(sig.ClassicalAlgorithm), Signature: sig.ClassicalSignature},
+	)
+}
@@ -0,0 +1,44 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Report formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// csvHeader names the columns WriteReport emits in CSV, in Finding's field order
+var csvHeader = []string{"signatureId", "docHash", "signerDid", "status", "txHash", "issue", "detail", "action", "fixError"}
+
+// WriteReport writes findings to w as a JSON array or as CSV with a header row
+func WriteReport(w io.Writer, format string, findings []Finding) error {
+	switch format {
+	case FormatJSON:
+		if findings == nil {
+			findings = []Finding{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(findings)
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, f := range findings {
+			if err := cw.Write([]string{f.SignatureID, f.DocHash, f.SignerDID, f.Status, f.TxHash, f.Issue, f.Detail, f.Action, f.FixError}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
@@ -0,0 +1,74 @@
+package signing
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// compositeMagic tags the encoding so an anchored composite can never be
+// mistaken for a bare post-quantum signature
+const compositeMagic = "INKLESS-COMPOSITE-V1"
+
+// ErrMalformedComposite is returned when composite bytes can't be decoded
+var ErrMalformedComposite = errors.New("malformed composite signature")
+
+// Component is one part of a composite signature
+type Component struct {
+	Algorithm Algorithm
+	Signature []byte
+}
+
+// EncodeComposite builds the deterministic byte string anchored on-chain for
+// a hybrid signature: the magic tag followed by each component as
+// u8 len(alg) || alg || u32 len(sig) || sig, post-quantum part first.
+// Committing to both parts means neither can be stripped after anchoring.
+func EncodeComposite(pq, classical Component) []byte {
+	size := len(compositeMagic)
+	for _, c := range []Component{pq, classical} {
+		size += 1 + len(c.Algorithm) + 4 + len(c.Signature)
+	}
+
+	out := make([]byte, 0, size)
+	out = append(out, compositeMagic...)
+	for _, c := range []Component{pq, classical} {
+		out = append(out, byte(len(c.Algorithm)))
+		out = append(out, c.Algorithm...)
+		out = binary.BigEndian.AppendUint32(out, uint32(len(c.Signature)))
+		out = append(out, c.Signature...)
+	}
+	return out
+}
+
+// DecodeComposite splits bytes produced by EncodeComposite
+func DecodeComposite(data []byte) (pq, classical Component, err error) {
+	if len(data) < len(compositeMagic) || string(data[:len(compositeMagic)]) != compositeMagic {
+		return Component{}, Component{}, ErrMalformedComposite
+	}
+	rest := data[len(compositeMagic):]
+
+	var parts [2]Component
+	for i := range parts {
+		if len(rest) < 1 {
+			return Component{}, Component{}, ErrMalformedComposite
+		}
+		algLen := int(rest[0])
+		rest = rest[1:]
+		if len(rest) < algLen+4 {
+			return Component{}, Component{}, ErrMalformedComposite
+		}
+		parts[i].Algorithm = Algorithm(rest[:algLen])
+		rest = rest[algLen:]
+		sigLen := int(binary.BigEndian.Uint32(rest[:4]))
+		rest = rest[4:]
+		if len(rest) < sigLen {
+			return Component{}, Component{}, ErrMalformedComposite
+		}
+		parts[i].Signature = rest[:sigLen]
+		rest = rest[sigLen:]
+	}
+	if len(rest) != 0 {
+		return Component{}, Component{}, ErrMalformedComposite
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,148 @@
+package signing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudflare/circl/sign/dilithium/mode2"
+	"github.com/cloudflare/circl/sign/dilithium/mode3"
+	"github.com/cloudflare/circl/sign/dilithium/mode5"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa87"
+)
+
+// Status is the policy state of an algorithm
+type Status string
+
+// Algorithm policy states
+const (
+	StatusActive     Status = "active"     // Accepted for new signatures
+	StatusDeprecated Status = "deprecated" // Still verifies, flagged in responses
+	StatusForbidden  Status = "forbidden"  // Rejected everywhere
+)
+
+// Spec describes a registered algorithm
+type Spec struct {
+	ID          Algorithm
+	Verifier    Verifier
+	PostQuantum bool // Acceptable as the pqcSignature of an anchor
+	Status      Status
+}
+
+// Registry maps algorithm IDs to verifiers and their policy status
+type Registry struct {
+	mu    sync.RWMutex
+	specs map[Algorithm]Spec
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{specs: make(map[Algorithm]Spec)}
+}
+
+// DefaultRegistry holds every algorithm the API knows about
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(Spec{ID: ECDSAP256, Verifier: VerifierFunc(verifyECDSAP256), Status: StatusActive})
+	r.Register(Spec{ID: Ed25519, Verifier: VerifierFunc(verifyEd25519), Status: StatusActive})
+	r.Register(Spec{ID: MLDSA44, Verifier: schemeVerifier(mldsa44.Scheme()), PostQuantum: true, Status: StatusActive})
+	r.Register(Spec{ID: MLDSA65, Verifier: schemeVerifier(mldsa65.Scheme()), PostQuantum: true, Status: StatusActive})
+	r.Register(Spec{ID: MLDSA87, Verifier: schemeVerifier(mldsa87.Scheme()), PostQuantum: true, Status: StatusActive})
+	r.Register(Spec{ID: Dilithium2R3, Verifier: schemeVerifier(mode2.Scheme()), PostQuantum: true, Status: StatusDeprecated})
+	r.Register(Spec{ID: Dilithium3R3, Verifier: schemeVerifier(mode3.Scheme()), PostQuantum: true, Status: StatusDeprecated})
+	r.Register(Spec{ID: Dilithium5R3, Verifier: schemeVerifier(mode5.Scheme()), PostQuantum: true, Status: StatusDeprecated})
+	return r
+}
+
+// Register adds or replaces an algorithm
+func (r *Registry) Register(spec Spec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if spec.Status == "" {
+		spec.Status = StatusActive
+	}
+	r.specs[spec.ID] = spec
+}
+
+// Lookup returns the spec for an algorithm
+func (r *Registry) Lookup(alg Algorithm) (Spec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.specs[alg]
+	if !ok {
+		return Spec{}, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+	return spec, nil
+}
+
+// Status returns the policy status of an algorithm. Unknown algorithms
+// (e.g. legacy rows recorded before the registry existed) report "".
+func (r *Registry) Status(alg Algorithm) Status {
+	spec, err := r.Lookup(alg)
+	if err != nil {
+		return ""
+	}
+	return spec.Status
+}
+
+// SetStatus changes the policy status of a registered algorithm
+func (r *Registry) SetStatus(alg Algorithm, status Status) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spec, ok := r.specs[alg]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+	spec.Status = status
+	r.specs[alg] = spec
+	return nil
+}
+
+// Algorithms lists registered algorithm IDs in sorted order
+func (r *Registry) Algorithms() []Algorithm {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]Algorithm, 0, len(r.specs))
+	for id := range r.specs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Verify checks a signature, refusing algorithms that policy forbids
+func (r *Registry) Verify(alg Algorithm, publicKey, message, signature []byte) error {
+	spec, err := r.Lookup(alg)
+	if err != nil {
+		return err
+	}
+	if spec.Status == StatusForbidden {
+		return fmt.Errorf("%w: %s", ErrAlgorithmForbidden, alg)
+	}
+	return spec.Verifier.Verify(publicKey, message, signature)
+}
+
+// ApplyPolicy marks algorithms deprecated or forbidden in the default
+// registry. Forbidden wins if an algorithm appears in both lists.
+func ApplyPolicy(deprecated, forbidden []string) error {
+	for _, list := range []struct {
+		ids    []string
+		status Status
+	}{{deprecated, StatusDeprecated}, {forbidden, StatusForbidden}} {
+		for _, id := range list.ids {
+			id = strings.ToLower(strings.TrimSpace(id))
+			if id == "" {
+				continue
+			}
+			if err := DefaultRegistry.SetStatus(Algorithm(id), list.status); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Package signing verifies device signatures made with the key types Inkless
+// clients hold: ECDSA P-256 (WebCrypto / Secure Enclave), FIPS 204 ML-DSA and
+// the legacy round-3 Dilithium it replaced. Algorithms are looked up in a
+// Registry so they can be deprecated or forbidden without a code change.
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/cloudflare/circl/sign"
+)
+
+// Algorithm identifies a signature scheme accepted by the API
+type Algorithm string
+
+// Known algorithms
+const (
+	ECDSAP256 Algorithm = "ecdsa-p256"
+	Ed25519   Algorithm = "ed25519"
+	MLDSA44   Algorithm = "ml-dsa-44"
+	MLDSA65   Algorithm = "ml-dsa-65"
+	MLDSA87   Algorithm = "ml-dsa-87"
+
+	// Pre-standard CRYSTALS-Dilithium (round 3), kept so signatures made
+	// before the FIPS 204 migration can still be re-verified
+	Dilithium2R3 Algorithm = "dilithium2-r3"
+	Dilithium3R3 Algorithm = "dilithium3-r3"
+	Dilithium5R3 Algorithm = "dilithium5-r3"
+)
+
+var (
+	// ErrUnsupportedAlgorithm is returned for an unknown algorithm identifier
+	ErrUnsupportedAlgorithm = errors.New("unsupported signature algorithm")
+	// ErrAlgorithmForbidden is returned when policy forbids an algorithm
+	ErrAlgorithmForbidden = errors.New("signature algorithm is forbidden by policy")
+	// ErrInvalidPublicKey is returned when a key can't be decoded for the algorithm
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrInvalidSignature is returned when a signature does not verify
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Verifier checks a signature over message with an encoded public key
+type Verifier interface {
+	Verify(publicKey, message, signature []byte) error
+}
+
+// VerifierFunc adapts a function to the Verifier interface
+type VerifierFunc func(publicKey, message, signature []byte) error
+
+// Verify implements Verifier
+func (f VerifierFunc) Verify(publicKey, message, signature []byte) error {
+	return f(publicKey, message, signature)
+}
+
+// ParseAlgorithm normalises an algorithm identifier and checks it is registered
+func ParseAlgorithm(s string) (Algorithm, error) {
+	alg := Algorithm(strings.ToLower(strings.TrimSpace(s)))
+	if _, err := DefaultRegistry.Lookup(alg); err != nil {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, s)
+	}
+	return alg, nil
+}
+
+// Verify checks signature with the default registry
+func Verify(alg Algorithm, publicKey, message, signature []byte) error {
+	return DefaultRegistry.Verify(alg, publicKey, message, signature)
+}
+
+// DecodePublicKey decodes a stored public key. Keys exported by the web
+// client are hex; native clients send standard or URL-safe base64.
+func DecodePublicKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if raw, err := hex.DecodeString(strings.TrimPrefix(encoded, "0x")); err == nil && len(raw) > 0 {
+		return raw, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if raw, err := enc.DecodeString(encoded); err == nil {
+			return raw, nil
+		}
+	}
+	return nil, ErrInvalidPublicKey
+}
+
+// KeyID is a stable fingerprint of a decoded public key: the first 16 bytes
+// of its SHA-256, hex-encoded. It is stored with each signature so the key
+// that produced it can be found again after rotation.
+func KeyID(publicKey []byte) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:16])
+}
+
+// schemeVerifier verifies raw-encoded keys and signatures for a circl scheme.
+// ML-DSA is used in pure mode with an empty context string.
+func schemeVerifier(scheme sign.Scheme) Verifier {
+	return VerifierFunc(func(publicKey, message, signature []byte) error {
+		pk, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+		}
+		if len(signature) != scheme.SignatureSize() || !scheme.Verify(pk, message, signature, nil) {
+			return ErrInvalidSignature
+		}
+		return nil
+	})
+}
+
+// verifyECDSAP256 accepts PKIX (SubjectPublicKeyInfo) DER keys or an
+// uncompressed point, and signatures in ASN.1 DER or the raw r||s form
+// WebCrypto produces, over SHA-256(message)
+func verifyECDSAP256(publicKey, message, signature []byte) error {
+	pub, err := parseP256PublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(message)
+
+	// WebCrypto emits IEEE P1363 r||s; everything else uses ASN.1 DER
+	if len(signature) == 64 {
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if ecdsa.Verify(pub, digest[:], r, s) {
+			return nil
+		}
+	}
+	if ecdsa.VerifyASN1(pub, digest[:], signature) {
+		return nil
+	}
+	return ErrInvalidSignature
+}
+
+// verifyEd25519 accepts raw 32-byte or PKIX DER keys
+func verifyEd25519(publicKey, message, signature []byte) error {
+	pub := ed25519.PublicKey(publicKey)
+	if len(publicKey) != ed25519.PublicKeySize {
+		parsed, err := x509.ParsePKIXPublicKey(publicKey)
+		if err != nil {
+			return ErrInvalidPublicKey
+		}
+		var ok bool
+		if pub, ok = parsed.(ed25519.PublicKey); !ok {
+			return fmt.Errorf("%w: not an Ed25519 key", ErrInvalidPublicKey)
+		}
+	}
+	if !ed25519.Verify(pub, message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func parseP256PublicKey(raw []byte) (*ecdsa.PublicKey, error) {
+	if parsed, err := x509.ParsePKIXPublicKey(raw); err == nil {
+		pub, ok := parsed.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: not a P-256 key", ErrInvalidPublicKey)
+		}
+		return pub, nil
+	}
+
+	// Uncompressed SEC1 point (0x04 || X || Y)
+	if len(raw) == 65 && raw[0] == 0x04 {
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(raw[1:33]),
+			Y:     new(big.Int).SetBytes(raw[33:]),
+		}
+		if pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return pub, nil
+		}
+	}
+
+	return nil, ErrInvalidPublicKey
+}
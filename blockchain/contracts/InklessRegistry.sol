@@ -19,6 +19,19 @@ contract InklessRegistry {
         bool isRevoked;           // For compliance-based revocation (NDPA 2023)
     }
 
+    // One signer's signature over a document. Signers are identified by
+    // keccak256 of their DID because every anchor is submitted by the API's
+    // relayer account, so msg.sender can't tell signers apart; a signer's own
+    // account, when it approved the anchor, is kept in signerAccounts.
+    struct SignerRecord {
+        bytes32 signerId;         // keccak256(DID)
+        address submitter;        // Account that anchored it
+        bytes pqcSignature;       // Post-quantum (or composite) signature
+        uint256 timestamp;        // Block time of anchoring
+        bytes32 hardwareID;       // Hash of the device TPM/Secure Enclave ID
+        bool isRevoked;
+    }
+
     // ============ State Variables ============
 
     // Mapping from document hash to signature record
@@ -27,12 +40,28 @@ contract InklessRegistry {
     // List of all anchored document hashes (for enumeration)
     bytes32[] public documentHashes;
 
+    // Multi-signer records: document hash => signer ID => record
+    mapping(bytes32 => mapping(bytes32 => SignerRecord)) public signerRecords;
+
+    // Signer IDs per document, in anchoring order
+    mapping(bytes32 => bytes32[]) private documentSigners;
+
     // Access control: verified DIDs that can anchor signatures
     mapping(address => bool) public verifiedSigners;
 
     // Contract owner (for admin functions)
     address public owner;
 
+    // Signer accounts: document hash => signer ID => the account that
+    // approved a relayed anchor. Zero for anchors only the relayer vouches for.
+    mapping(bytes32 => mapping(bytes32 => address)) public signerAccounts;
+
+    // EIP-712 domain and the typed data a signer account signs to approve an anchor
+    bytes32 public immutable DOMAIN_SEPARATOR;
+    bytes32 public constant ANCHOR_TYPEHASH = keccak256(
+        "Anchor(bytes32 docHash,bytes32 signerId,bytes32 signatureHash,bytes32 hardwareID)"
+    );
+
     // ============ Events ============
 
     event SignatureAnchored(
@@ -42,12 +71,26 @@ contract InklessRegistry {
         bytes32 hardwareID
     );
 
+    event SignerAnchored(
+        bytes32 indexed docHash,
+        bytes32 indexed signerId,
+        uint256 timestamp,
+        bytes32 hardwareID
+    );
+
     event SignatureRevoked(
         bytes32 indexed docHash,
         address indexed revoker,
         uint256 timestamp
     );
 
+    event SignerRevoked(
+        bytes32 indexed docHash,
+        bytes32 indexed signerId,
+        address indexed revoker,
+        uint256 timestamp
+    );
+
     event SignerVerified(
         address indexed signerDID,
         uint256 timestamp
@@ -85,6 +128,13 @@ contract InklessRegistry {
     constructor() {
         owner = msg.sender;
         verifiedSigners[msg.sender] = true;
+        DOMAIN_SEPARATOR = keccak256(abi.encode(
+            keccak256("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"),
+            keccak256("InklessRegistry"),
+            keccak256("1"),
+            block.chainid,
+            address(this)
+        ));
     }
 
     // ============ Core Functions ============
@@ -118,6 +168,100 @@ contract InklessRegistry {
         emit SignatureAnchored(_docHash, msg.sender, block.timestamp, _hardwareID);
     }
 
+    /**
+     * @dev Anchor one signer's signature; any number of signers may sign a document
+     * @param _docHash SHA-3 hash of the document
+     * @param _signerId keccak256 of the signer's DID
+     * @param _pqcSignature Post-quantum (or composite) signature bytes
+     * @param _hardwareID Hash of the signer's device hardware ID
+     */
+    function anchorSignerSignature(
+        bytes32 _docHash,
+        bytes32 _signerId,
+        bytes calldata _pqcSignature,
+        bytes32 _hardwareID
+    ) external onlyVerifiedSigner {
+        _anchorSigner(_docHash, _signerId, _pqcSignature, _hardwareID);
+    }
+
+    /**
+     * @dev Relay one signer's signature approved by the signer's own account.
+     * The account signs the EIP-712 Anchor typed data and must be a verified
+     * signer; it is recorded in signerAccounts, so the anchor is attributable
+     * to the signer rather than to the relayer that sent it.
+     * @param _docHash SHA-3 hash of the document
+     * @param _signerId keccak256 of the signer's DID
+     * @param _pqcSignature Post-quantum (or composite) signature bytes
+     * @param _hardwareID Hash of the signer's device hardware ID
+     * @param _account The signer's account
+     * @param _accountSignature The account's 65-byte signature over the Anchor typed data
+     */
+    function anchorSignerSignatureFor(
+        bytes32 _docHash,
+        bytes32 _signerId,
+        bytes calldata _pqcSignature,
+        bytes32 _hardwareID,
+        address _account,
+        bytes calldata _accountSignature
+    ) external onlyVerifiedSigner {
+        require(verifiedSigners[_account], "Account is not a verified signer");
+        require(
+            _recover(_anchorDigest(_docHash, _signerId, _pqcSignature, _hardwareID), _accountSignature) == _account,
+            "Invalid account signature"
+        );
+
+        _anchorSigner(_docHash, _signerId, _pqcSignature, _hardwareID);
+        signerAccounts[_docHash][_signerId] = _account;
+    }
+
+    /**
+     * @dev Record one signer's anchor, sent by msg.sender
+     */
+    function _anchorSigner(
+        bytes32 _docHash,
+        bytes32 _signerId,
+        bytes calldata _pqcSignature,
+        bytes32 _hardwareID
+    ) private {
+        require(_docHash != bytes32(0), "Invalid document hash");
+        require(_signerId != bytes32(0), "Invalid signer");
+        require(_pqcSignature.length > 0, "Invalid signature");
+        require(_hardwareID != bytes32(0), "Invalid hardware ID");
+        require(signerRecords[_docHash][_signerId].timestamp == 0, "Signer already anchored");
+
+        if (documentSigners[_docHash].length == 0 && signatures[_docHash].timestamp == 0) {
+            documentHashes.push(_docHash);
+        }
+
+        signerRecords[_docHash][_signerId] = SignerRecord({
+            signerId: _signerId,
+            submitter: msg.sender,
+            pqcSignature: _pqcSignature,
+            timestamp: block.timestamp,
+            hardwareID: _hardwareID,
+            isRevoked: false
+        });
+        documentSigners[_docHash].push(_signerId);
+
+        emit SignerAnchored(_docHash, _signerId, block.timestamp, _hardwareID);
+    }
+
+    /**
+     * @dev Get every signer record anchored for a document
+     * @param _docHash SHA-3 hash of the document
+     */
+    function getDocumentSigners(bytes32 _docHash)
+        external
+        view
+        returns (SignerRecord[] memory records)
+    {
+        bytes32[] storage ids = documentSigners[_docHash];
+        records = new SignerRecord[](ids.length);
+        for (uint256 i = 0; i < ids.length; i++) {
+            records[i] = signerRecords[_docHash][ids[i]];
+        }
+    }
+
     /**
      * @dev Verify a document signature exists and is valid
      * @param _docHash SHA-3 hash of the document to verify
@@ -184,6 +328,29 @@ contract InklessRegistry {
         emit SignatureRevoked(_docHash, msg.sender, block.timestamp);
     }
 
+    /**
+     * @dev Revoke one signer's signature on a document
+     * Only the account that anchored it, the signer account that approved it
+     * or the contract owner can revoke
+     * @param _docHash SHA-3 hash of the document
+     * @param _signerId keccak256 of the signer's DID
+     */
+    function revokeSignerSignature(bytes32 _docHash, bytes32 _signerId) external {
+        SignerRecord storage record = signerRecords[_docHash][_signerId];
+        require(record.timestamp != 0, "Signer not found");
+        address account = signerAccounts[_docHash][_signerId];
+        require(
+            msg.sender == record.submitter || msg.sender == owner ||
+                (account != address(0) && msg.sender == account),
+            "Not authorized to revoke"
+        );
+        require(!record.isRevoked, "Already revoked");
+
+        record.isRevoked = true;
+
+        emit SignerRevoked(_docHash, _signerId, msg.sender, block.timestamp);
+    }
+
     // ============ Admin Functions ============
 
     /**
@@ -221,6 +388,44 @@ contract InklessRegistry {
         owner = _newOwner;
     }
 
+    // ============ Internal Functions ============
+
+    /**
+     * @dev EIP-712 digest of the Anchor typed data a signer account approves
+     */
+    function _anchorDigest(
+        bytes32 _docHash,
+        bytes32 _signerId,
+        bytes calldata _pqcSignature,
+        bytes32 _hardwareID
+    ) private view returns (bytes32) {
+        return keccak256(abi.encodePacked(
+            "\x19\x01",
+            DOMAIN_SEPARATOR,
+            keccak256(abi.encode(ANCHOR_TYPEHASH, _docHash, _signerId, keccak256(_pqcSignature), _hardwareID))
+        ));
+    }
+
+    /**
+     * @dev Recover the signer of a digest from a 65-byte (r, s, v) signature,
+     * rejecting malleable high-s signatures
+     */
+    function _recover(bytes32 _digest, bytes calldata _signature) private pure returns (address) {
+        require(_signature.length == 65, "Invalid signature length");
+        bytes32 r = bytes32(_signature[0:32]);
+        bytes32 s = bytes32(_signature[32:64]);
+        uint8 v = uint8(_signature[64]);
+        require(
+            uint256(s) <= 0x7FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF5D576E7357A4501DDFE92F46681B20A0,
+            "Invalid signature s value"
+        );
+        require(v == 27 || v == 28, "Invalid signature v value");
+
+        address signer = ecrecover(_digest, v, r, s);
+        require(signer != address(0), "Invalid signature");
+        return signer;
+    }
+
     // ============ View Functions ============
 
     /**
@@ -243,6 +448,6 @@ contract InklessRegistry {
      * @param _docHash SHA-3 hash to check
      */
     function isDocumentAnchored(bytes32 _docHash) external view returns (bool) {
-        return signatures[_docHash].timestamp != 0;
+        return signatures[_docHash].timestamp != 0 || documentSigners[_docHash].length != 0;
     }
 }